@@ -0,0 +1,196 @@
+//Package analytics calculates statistics of the rates of history series.
+//
+//Results are returned as response.Series aligned with the dates of the
+//given series. Missing rates and filled dates (see response.Series.Fill)
+//are skipped, so statistics are calculated over the observed rates only
+//and the values of the skipped dates are NaN.
+package analytics
+
+import (
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"math"
+)
+
+//TradingDaysPerYear is the usual annualization factor of daily rates
+const TradingDaysPerYear = 252
+
+//Drawdown is the largest decline of a rate from its peak
+type Drawdown struct {
+	//Value is the decline relative to the peak, 0.1 means 10%
+	Value  float64
+	Peak   gtime.Gexc
+	Trough gtime.Gexc
+}
+
+//LogReturns calculates the logarithmic returns of each currency.
+//Return of a date is calculated against the previous observed rate.
+func LogReturns(s response.Series) response.Series {
+	return apply(s, func(rates []float64, observed []int) []float64 {
+		values := nanSlice(len(rates))
+		for n := 1; n < len(observed); n++ {
+			values[observed[n]] = math.Log(rates[observed[n]] / rates[observed[n-1]])
+		}
+
+		return values
+	})
+}
+
+//Volatility calculates the rolling standard deviation of the log returns
+//over the given number of returns. Result is multiplied by the square root
+//of periodsPerYear for annualization, zero periodsPerYear disables it.
+func Volatility(s response.Series, window int, periodsPerYear float64) response.Series {
+	factor := 1.0
+	if periodsPerYear > 0 {
+		factor = math.Sqrt(periodsPerYear)
+	}
+
+	return apply(LogReturns(s), func(returns []float64, observed []int) []float64 {
+		values := nanSlice(len(returns))
+		if window < 2 {
+			return values
+		}
+
+		for n := window - 1; n < len(observed); n++ {
+			var sample []float64
+			for _, i := range observed[n-window+1 : n+1] {
+				sample = append(sample, returns[i])
+			}
+
+			values[observed[n]] = stddev(sample) * factor
+		}
+
+		return values
+	})
+}
+
+//SMA calculates the simple moving average of the rates
+//over the given number of observed rates
+func SMA(s response.Series, window int) response.Series {
+	return apply(s, func(rates []float64, observed []int) []float64 {
+		values := nanSlice(len(rates))
+		if window < 1 {
+			return values
+		}
+
+		sum := 0.0
+		for n, i := range observed {
+			sum += rates[i]
+			if n >= window {
+				sum -= rates[observed[n-window]]
+			}
+
+			if n >= window-1 {
+				values[i] = sum / float64(window)
+			}
+		}
+
+		return values
+	})
+}
+
+//EMA calculates the exponential moving average of the rates.
+//Smoothing factor is 2/(span+1) and the average starts with the first rate.
+func EMA(s response.Series, span int) response.Series {
+	alpha := 2 / (float64(span) + 1)
+
+	return apply(s, func(rates []float64, observed []int) []float64 {
+		values := nanSlice(len(rates))
+		if span < 1 {
+			return values
+		}
+
+		for n, i := range observed {
+			if n == 0 {
+				values[i] = rates[i]
+				continue
+			}
+
+			values[i] = alpha*rates[i] + (1-alpha)*values[observed[n-1]]
+		}
+
+		return values
+	})
+}
+
+//MaxDrawdown calculates the largest decline from a peak for each currency.
+//Currencies without any observed rate are not included.
+func MaxDrawdown(s response.Series) map[string]Drawdown {
+	drawdowns := make(map[string]Drawdown, len(s.Rates))
+
+	for code, rates := range s.Rates {
+		observed := observedIndexes(s, rates)
+		if len(observed) == 0 {
+			continue
+		}
+
+		peak := observed[0]
+		drawdown := Drawdown{Peak: s.Dates[peak], Trough: s.Dates[peak]}
+		for _, i := range observed {
+			if rates[i] > rates[peak] {
+				peak = i
+			}
+
+			if value := (rates[peak] - rates[i]) / rates[peak]; value > drawdown.Value {
+				drawdown = Drawdown{Value: value, Peak: s.Dates[peak], Trough: s.Dates[i]}
+			}
+		}
+
+		drawdowns[code] = drawdown
+	}
+
+	return drawdowns
+}
+
+//apply calculates new values for each currency of the series
+//by passing the indexes of the observed rates to fn
+func apply(s response.Series, fn func(rates []float64, observed []int) []float64) response.Series {
+	result := response.Series{
+		Base:  s.Base,
+		Dates: append([]gtime.Gexc{}, s.Dates...),
+		Rates: make(map[string][]float64, len(s.Rates)),
+		Kinds: append([]response.PointKind(nil), s.Kinds...),
+	}
+
+	for code, rates := range s.Rates {
+		result.Rates[code] = fn(rates, observedIndexes(s, rates))
+	}
+
+	return result
+}
+
+func observedIndexes(s response.Series, rates []float64) []int {
+	var observed []int
+	for i, rate := range rates {
+		if !math.IsNaN(rate) && !s.IsSynthetic(i) {
+			observed = append(observed, i)
+		}
+	}
+
+	return observed
+}
+
+func stddev(values []float64) float64 {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(values)-1))
+}
+
+func nanSlice(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+
+	return values
+}
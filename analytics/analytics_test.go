@@ -0,0 +1,154 @@
+package analytics
+
+import (
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"testing"
+)
+
+var nan = math.NaN()
+
+//testSeries has a missing TRY rate on 2020-12-24 and
+//non-publication days filled with previous rates
+func testSeries(t *testing.T) response.Series {
+	history := response.History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-12-21": {"TRY": 10, "USD": 1.0},
+			"2020-12-22": {"TRY": 11, "USD": 1.1},
+			"2020-12-23": {"TRY": 9, "USD": 1.2},
+			"2020-12-24": {"USD": 1.3},
+			"2020-12-28": {"TRY": 12, "USD": 1.2},
+		},
+	}
+
+	series, err := history.Fill(nil, response.FillPrevious)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return series
+}
+
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if math.IsNaN(a[i]) != math.IsNaN(b[i]) || math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestLogReturns(t *testing.T) {
+	got := LogReturns(testSeries(t))
+
+	tests := []struct {
+		code string
+		want []float64
+	}{
+		{
+			code: "TRY",
+			want: []float64{nan, math.Log(1.1), math.Log(9.0 / 11), nan, nan, nan, nan, math.Log(12.0 / 9)},
+		},
+		{
+			code: "USD",
+			want: []float64{nan, math.Log(1.1), math.Log(1.2 / 1.1), math.Log(1.3 / 1.2), nan, nan, nan, math.Log(1.2 / 1.3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if !equal(got.Rates[tt.code], tt.want) {
+				t.Errorf("LogReturns() = %v, want %v", got.Rates[tt.code], tt.want)
+			}
+		})
+	}
+}
+
+func TestVolatility(t *testing.T) {
+	returns := []float64{math.Log(1.1), math.Log(9.0 / 11), math.Log(12.0 / 9)}
+
+	tests := []struct {
+		name           string
+		window         int
+		periodsPerYear float64
+		want           []float64
+	}{
+		{
+			name:   "should calculate rolling standard deviation of returns",
+			window: 2,
+			want: []float64{
+				nan, nan, stddev(returns[:2]), nan, nan, nan, nan, stddev(returns[1:]),
+			},
+		},
+		{
+			name:           "should annualize the volatility",
+			window:         3,
+			periodsPerYear: TradingDaysPerYear,
+			want: []float64{
+				nan, nan, nan, nan, nan, nan, nan, stddev(returns) * math.Sqrt(TradingDaysPerYear),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Volatility(testSeries(t), tt.window, tt.periodsPerYear)
+			if !equal(got.Rates["TRY"], tt.want) {
+				t.Errorf("Volatility() = %v, want %v", got.Rates["TRY"], tt.want)
+			}
+		})
+	}
+}
+
+func TestSMA(t *testing.T) {
+	got := SMA(testSeries(t), 2)
+	want := []float64{nan, 10.5, 10, nan, nan, nan, nan, 10.5}
+
+	if !equal(got.Rates["TRY"], want) {
+		t.Errorf("SMA() = %v, want %v", got.Rates["TRY"], want)
+	}
+}
+
+func TestEMA(t *testing.T) {
+	got := EMA(testSeries(t), 3)
+
+	second := 0.5*11 + 0.5*10
+	third := 0.5*9 + 0.5*second
+	want := []float64{10, second, third, nan, nan, nan, nan, 0.5*12 + 0.5*third}
+
+	if !equal(got.Rates["TRY"], want) {
+		t.Errorf("EMA() = %v, want %v", got.Rates["TRY"], want)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	got := MaxDrawdown(testSeries(t))
+
+	tests := []struct {
+		code       string
+		wantValue  float64
+		wantPeak   string
+		wantTrough string
+	}{
+		{code: "TRY", wantValue: 2.0 / 11, wantPeak: "2020-12-22", wantTrough: "2020-12-23"},
+		{code: "USD", wantValue: 0.1 / 1.3, wantPeak: "2020-12-24", wantTrough: "2020-12-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			drawdown := got[tt.code]
+			if math.Abs(drawdown.Value-tt.wantValue) > 1e-9 ||
+				drawdown.Peak.String() != tt.wantPeak || drawdown.Trough.String() != tt.wantTrough {
+				t.Errorf("MaxDrawdown() = %v %v %v, want %v %v %v", drawdown.Value, drawdown.Peak,
+					drawdown.Trough, tt.wantValue, tt.wantPeak, tt.wantTrough)
+			}
+		})
+	}
+}
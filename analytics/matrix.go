@@ -0,0 +1,155 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc/response"
+	"math"
+	"strings"
+)
+
+var (
+	ErrCurrencyNotFound = errors.New("currency not found in history")
+)
+
+//Matrix is a square matrix whose rows and columns are labeled by currencies
+type Matrix struct {
+	Labels []string
+	Values [][]float64
+}
+
+//At returns the value of the given row and column
+func (m Matrix) At(row, column string) (float64, bool) {
+	i, j := m.index(row), m.index(column)
+	if i < 0 || j < 0 {
+		return 0, false
+	}
+
+	return m.Values[i][j], true
+}
+
+func (m Matrix) index(label string) int {
+	for i, l := range m.Labels {
+		if l == label {
+			return i
+		}
+	}
+
+	return -1
+}
+
+//MatrixOptions customizes the calculation of the matrices
+type MatrixOptions struct {
+	//Rebase recalculates the rates against the given currency before
+	//the returns are calculated, so the results do not depend on the base
+	//of the history. Old base becomes one of the currencies.
+	Rebase string
+	//Currencies limits the matrix to the given currencies.
+	//All currencies of the history are used if it is empty.
+	//Returns ErrCurrencyNotFound for the currencies that are not
+	//in the history, including its base if it is not rebased.
+	Currencies []string
+}
+
+//Covariance calculates the sample covariance matrix of the daily log returns
+//of the currencies. Each pair is calculated over the dates that both of the
+//currencies have returns, NaN is used if there are less than two of them.
+func Covariance(h response.History, options MatrixOptions) (Matrix, error) {
+	return matrix(h, options, covariance)
+}
+
+//Correlation calculates the correlation matrix of the daily log returns
+//of the currencies, see Covariance for the handling of missing rates
+func Correlation(h response.History, options MatrixOptions) (Matrix, error) {
+	return matrix(h, options, func(x, y []float64) float64 {
+		return covariance(x, y) / math.Sqrt(covariance(x, x)*covariance(y, y))
+	})
+}
+
+func matrix(h response.History, options MatrixOptions, fn func(x, y []float64) float64) (Matrix, error) {
+	series, err := h.Series()
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	if options.Rebase != "" {
+		series, err = series.Rebase(strings.ToUpper(options.Rebase))
+		if err != nil {
+			return Matrix{}, err
+		}
+	}
+
+	returns := LogReturns(series)
+
+	labels := make([]string, 0, len(options.Currencies))
+	for _, code := range options.Currencies {
+		code = strings.ToUpper(code)
+		if _, ok := returns.Rates[code]; !ok {
+			return Matrix{}, fmt.Errorf("%w: %v", ErrCurrencyNotFound, code)
+		}
+
+		labels = append(labels, code)
+	}
+
+	if len(labels) == 0 {
+		labels = returns.Currencies()
+	}
+
+	m := Matrix{
+		Labels: labels,
+		Values: make([][]float64, len(labels)),
+	}
+
+	for i, row := range labels {
+		m.Values[i] = make([]float64, len(labels))
+		for j, column := range labels {
+			if j < i {
+				m.Values[i][j] = m.Values[j][i]
+				continue
+			}
+
+			x, y := pairwise(returns.Rates[row], returns.Rates[column])
+			m.Values[i][j] = fn(x, y)
+		}
+	}
+
+	return m, nil
+}
+
+//pairwise returns the values of the indexes that both of the slices have
+func pairwise(a, b []float64) ([]float64, []float64) {
+	var x, y []float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if !math.IsNaN(a[i]) && !math.IsNaN(b[i]) {
+			x = append(x, a[i])
+			y = append(y, b[i])
+		}
+	}
+
+	return x, y
+}
+
+func covariance(x, y []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+
+	meanX, meanY := mean(x), mean(y)
+
+	sum := 0.0
+	for i := range x {
+		sum += (x[i] - meanX) * (y[i] - meanY)
+	}
+
+	return sum / float64(len(x)-1)
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
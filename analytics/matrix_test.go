@@ -0,0 +1,121 @@
+package analytics
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"reflect"
+	"testing"
+)
+
+//matrixHistory has TRY rates that are proportional to
+//the square of USD rates, so TRY returns are twice of USD ones
+func matrixHistory() response.History {
+	usd := []float64{1, 1.1, 1.05, 1.2}
+	dates := []string{"2020-12-21", "2020-12-22", "2020-12-23", "2020-12-24"}
+
+	rates := types.TimeRateItem{}
+	for i, date := range dates {
+		rates[date] = types.RateItem{"USD": usd[i], "TRY": 4 * usd[i] * usd[i]}
+	}
+
+	return response.History{Base: "EUR", Rates: rates}
+}
+
+func TestCovariance(t *testing.T) {
+	usd := []float64{math.Log(1.1), math.Log(1.05 / 1.1), math.Log(1.2 / 1.05)}
+	variance := covariance(usd, usd)
+
+	got, err := Covariance(matrixHistory(), MatrixOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got.Labels, []string{"TRY", "USD"}) {
+		t.Errorf("Covariance() labels = %v", got.Labels)
+	}
+
+	want := [][]float64{{4 * variance, 2 * variance}, {2 * variance, variance}}
+	for i := range want {
+		if !equal(got.Values[i], want[i]) {
+			t.Errorf("Covariance() row %d = %v, want %v", i, got.Values[i], want[i])
+		}
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name    string
+		options MatrixOptions
+		row     string
+		column  string
+		want    float64
+		wantErr error
+	}{
+		{
+			name:   "should calculate correlation of the currencies",
+			row:    "TRY",
+			column: "USD",
+			want:   1,
+		},
+		{
+			name:    "should rebase the history before calculation",
+			options: MatrixOptions{Rebase: "USD"},
+			row:     "EUR",
+			column:  "TRY",
+			want:    -1,
+		},
+		{
+			name:    "should limit the matrix to given currencies",
+			options: MatrixOptions{Currencies: []string{"USD"}},
+			row:     "USD",
+			column:  "USD",
+			want:    1,
+		},
+		{
+			name:    "should accept lower case currencies",
+			options: MatrixOptions{Rebase: "usd", Currencies: []string{"try", "eur"}},
+			row:     "EUR",
+			column:  "TRY",
+			want:    -1,
+		},
+		{
+			name:    "should raise an error for unknown currencies",
+			options: MatrixOptions{Currencies: []string{"USD", "GBP"}},
+			wantErr: ErrCurrencyNotFound,
+		},
+		{
+			name:    "should raise an error for the base without rebasing",
+			options: MatrixOptions{Currencies: []string{"USD", "EUR"}},
+			wantErr: ErrCurrencyNotFound,
+		},
+		{
+			name:    "should raise an error if new base does not exist",
+			options: MatrixOptions{Rebase: "GBP"},
+			wantErr: response.ErrBaseNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Correlation(matrixHistory(), tt.options)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Correlation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			value, ok := got.At(tt.row, tt.column)
+			if !ok || math.Abs(value-tt.want) > 1e-9 {
+				t.Errorf("Correlation() %v/%v = %v, want %v", tt.row, tt.column, value, tt.want)
+			}
+
+			if len(tt.options.Currencies) > 0 && len(got.Labels) != len(tt.options.Currencies) {
+				t.Errorf("Correlation() labels = %v", got.Labels)
+			}
+		})
+	}
+}
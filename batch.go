@@ -0,0 +1,184 @@
+package gexc
+
+import (
+	"fmt"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+//BatchItem is a single conversion of the batch.
+//Zero Date means the latest rates are used.
+type BatchItem struct {
+	Amount float64
+	From   string
+	To     string
+	Date   time.Time
+}
+
+//BatchResult is the outcome of the BatchItem at the same index.
+//Err is set if the item could not be converted.
+type BatchResult struct {
+	Conversion response.Conversion
+	Err        error
+}
+
+type batchGroup struct {
+	date    time.Time
+	base    string
+	symbols map[string]bool
+	indexes []int
+}
+
+//ConvertBatch converts all items with the minimum number of requests.
+//Items are grouped by their dates and a single rate table is fetched
+//for each date, the other pairs are derived from it as cross rates.
+//Tables are fetched concurrently, see WithConcurrency.
+//Results are returned in the order of the items.
+func (f *Fx) ConvertBatch(items []BatchItem) []BatchResult {
+	results := make([]BatchResult, len(items))
+	groups := make(map[string]*batchGroup)
+	var keys []string
+
+	for i, item := range items {
+		from, ok := CurrencyByCode(item.From)
+		if !ok {
+			results[i].Err = fmt.Errorf("%w: %v", ErrUnsupportedCurrency, item.From)
+			continue
+		}
+
+		to, ok := CurrencyByCode(item.To)
+		if !ok {
+			results[i].Err = fmt.Errorf("%w: %v", ErrUnsupportedCurrency, item.To)
+			continue
+		}
+
+		var date time.Time
+		if !item.Date.Equal(time.Time{}) {
+			date = truncateDay(item.Date)
+			if date.After(f.now()) {
+				results[i].Err = fmt.Errorf("%w: date should not be in the future", ErrInvalidParameter)
+				continue
+			}
+		}
+
+		key := date.Format(gtime.GexcLayout)
+		group, ok := groups[key]
+		if !ok {
+			group = &batchGroup{date: date, base: from.Code, symbols: make(map[string]bool)}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+
+		group.symbols[from.Code] = true
+		group.symbols[to.Code] = true
+		group.indexes = append(group.indexes, i)
+
+		results[i].Conversion = response.Conversion{
+			Amount: item.Amount,
+			From:   from.Code,
+			To:     to.Code,
+		}
+
+		if !date.IsZero() {
+			results[i].Conversion.RequestedDate = gtime.NewGexc(date)
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, f.workers())
+
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(group *batchGroup) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			f.convertGroup(group, results)
+		}(groups[key])
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (f *Fx) convertGroup(group *batchGroup, results []BatchResult) {
+	var symbols []string
+	for code := range group.symbols {
+		if code != group.base {
+			symbols = append(symbols, code)
+		}
+	}
+
+	sort.Strings(symbols)
+
+	var resp response.SingleDate
+	var err error
+	if group.date.IsZero() {
+		resp, err = f.BasedOn(group.base).Against(symbols...).Latest()
+	} else {
+		resp, err = f.BasedOn(group.base).Against(symbols...).At(group.date)
+		if err == nil && !resp.Date.IsZero() {
+			err = checkPublished(truncateDay(resp.Date.Time), group.date)
+		}
+	}
+
+	for _, i := range group.indexes {
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		conversion := &results[i].Conversion
+		rate, err := crossRate(resp.Rates, group.base, conversion.From, conversion.To)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		conversion.Rate = rate
+		if !resp.Date.IsZero() {
+			conversion.Date = gtime.NewGexc(truncateDay(resp.Date.Time))
+		}
+
+		if err := f.price(conversion); err != nil {
+			results[i] = BatchResult{Err: err}
+		}
+	}
+}
+
+//crossRate calculates the rate of from->to pair
+//by using the rates that are based on base currency
+func crossRate(rates types.RateItem, base, from, to string) (float64, error) {
+	rateOf := func(code string) (float64, error) {
+		if code == base {
+			return 1, nil
+		}
+
+		rate, ok := rates[code]
+		if !ok || rate == 0 {
+			return 0, fmt.Errorf("%w: %v", ErrCurrencyNotFound, code)
+		}
+
+		return rate, nil
+	}
+
+	fromRate, err := rateOf(from)
+	if err != nil {
+		return 0, err
+	}
+
+	toRate, err := rateOf(to)
+	if err != nil {
+		return 0, err
+	}
+
+	return toRate / fromRate, nil
+}
@@ -0,0 +1,151 @@
+package gexc
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	time2 "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+//batchTestClient serves the same EUR based table for all dates
+//and counts the requests
+type batchTestClient struct {
+	//published overrides the dates of the tables of the requested days
+	published map[string]time.Time
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (b *batchTestClient) table(base string, date time.Time) *response.SingleDate {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+
+	eur := types.RateItem{"EUR": 1, "TRY": 8, "USD": 1.5, "GBP": 0.5}
+	rates := types.RateItem{}
+	for code, rate := range eur {
+		rates[code] = rate / eur[base]
+	}
+
+	return &response.SingleDate{Base: base, Rates: rates, Date: time2.NewGexc(date)}
+}
+
+func (b *batchTestClient) Latest(params openex.LatestParams) (*response.SingleDate, error) {
+	return b.table(params.Base, time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC)), nil
+}
+
+func (b *batchTestClient) SingleDate(params openex.SingleDateParams) (*response.SingleDate, error) {
+	if date, ok := b.published[params.Date.String()]; ok {
+		return b.table(params.Base, date), nil
+	}
+
+	return b.table(params.Base, params.Date.Time), nil
+}
+
+func (b *batchTestClient) History(params openex.HistoryParams) (*response.History, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestFx_ConvertBatch(t *testing.T) {
+	date := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+	day := time2.NewGexc(time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC))
+	latest := time2.NewGexc(time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		items     []BatchItem
+		want      []response.Conversion
+		wantErrs  []bool
+		wantCalls int
+	}{
+		{
+			name: "should fetch a single table for each date and keep the order",
+			items: []BatchItem{
+				{Amount: 8, From: "TRY", To: "EUR"},
+				{Amount: 1, From: "GBP", To: "try", Date: date},
+				{Amount: 10, From: "EUR", To: "USD"},
+				{Amount: 2, From: "USD", To: "GBP", Date: date},
+			},
+			want: []response.Conversion{
+				{Amount: 8, From: "TRY", To: "EUR", Rate: 0.125, CustomerRate: 0.125, Result: 1, Date: latest},
+				{Amount: 1, From: "GBP", To: "TRY", Rate: 16, CustomerRate: 16, Result: 16, RequestedDate: day, Date: day},
+				{Amount: 10, From: "EUR", To: "USD", Rate: 1.5, CustomerRate: 1.5, Result: 15, Date: latest},
+				{Amount: 2, From: "USD", To: "GBP", Rate: 1.0 / 3, CustomerRate: 1.0 / 3, Result: 2.0 / 3, RequestedDate: day, Date: day},
+			},
+			wantErrs:  []bool{false, false, false, false},
+			wantCalls: 2,
+		},
+		{
+			name: "should report errors of invalid items without affecting others",
+			items: []BatchItem{
+				{Amount: 1, From: "UNKNOWN", To: "EUR"},
+				{Amount: 1, From: "EUR", To: "TRY", Date: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{Amount: 1, From: "EUR", To: "TRY"},
+			},
+			want: []response.Conversion{
+				{},
+				{},
+				{Amount: 1, From: "EUR", To: "TRY", Rate: 8, CustomerRate: 8, Result: 8, Date: latest},
+			},
+			wantErrs:  []bool{true, true, false},
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &batchTestClient{}
+			clock := time2.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC))
+			f := newFxWithClient(client, WithConcurrency(2), WithClock(clock))
+
+			got := f.ConvertBatch(tt.items)
+			if len(got) != len(tt.items) {
+				t.Fatalf("ConvertBatch() len = %v, want %v", len(got), len(tt.items))
+			}
+
+			for i := range got {
+				if (got[i].Err != nil) != tt.wantErrs[i] {
+					t.Errorf("ConvertBatch()[%d] error = %v, wantErr %v", i, got[i].Err, tt.wantErrs[i])
+					continue
+				}
+
+				if !tt.wantErrs[i] && !reflect.DeepEqual(got[i].Conversion, tt.want[i]) {
+					t.Errorf("ConvertBatch()[%d] got = %v, want %v", i, got[i].Conversion, tt.want[i])
+				}
+			}
+
+			if client.calls != tt.wantCalls {
+				t.Errorf("ConvertBatch() calls = %v, want %v", client.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFx_ConvertBatchPublishedAfter(t *testing.T) {
+	requested := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	client := &batchTestClient{published: map[string]time.Time{"2020-12-01": requested.AddDate(0, 0, 1)}}
+	clock := time2.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC))
+	f := newFxWithClient(client, WithClock(clock))
+
+	if _, err := f.ConvertAt(1, "EUR", "TRY", requested); !errors.Is(err, ErrClientFailed) {
+		t.Fatalf("ConvertAt() error = %v, want %v", err, ErrClientFailed)
+	}
+
+	got := f.ConvertBatch([]BatchItem{
+		{Amount: 1, From: "EUR", To: "TRY", Date: requested},
+		{Amount: 1, From: "USD", To: "TRY", Date: requested},
+		{Amount: 1, From: "EUR", To: "TRY", Date: requested.AddDate(0, 0, 1)},
+	})
+
+	for i, wantErr := range []bool{true, true, false} {
+		if (got[i].Err != nil) != wantErr || (wantErr && !errors.Is(got[i].Err, ErrClientFailed)) {
+			t.Errorf("ConvertBatch()[%d] error = %v, wantErr %v", i, got[i].Err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gexc
+
+import (
+	"fmt"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"time"
+)
+
+//Change compares the rates of two dates.
+//Dates that rates are not published on are resolved to the previous
+//publication day, see WithCalendar. StartAt and EndAt of the result
+//are the publication dates of the compared rates.
+func (f *fxRatesFromWrapper) Change(from, to time.Time) (response.Change, error) {
+	if from.Equal(time.Time{}) || to.Equal(time.Time{}) {
+		return response.Change{}, fmt.Errorf("%w: time values should not be empty", ErrInvalidParameter)
+	}
+
+	if to.Before(from) {
+		return response.Change{}, fmt.Errorf("%w: to value should not be smaller than from", ErrInvalidParameter)
+	}
+
+	if truncateDay(to).After(f.base.now()) {
+		return response.Change{}, fmt.Errorf("%w: to value should not be in the future", ErrInvalidParameter)
+	}
+
+	startDay, err := gtime.LatestPublicationDay(f.base.calendar, from)
+	if err != nil {
+		return response.Change{}, err
+	}
+
+	endDay, err := gtime.LatestPublicationDay(f.base.calendar, to)
+	if err != nil {
+		return response.Change{}, err
+	}
+
+	start, err := f.At(startDay)
+	if err != nil {
+		return response.Change{}, err
+	}
+
+	end, err := f.At(endDay)
+	if err != nil {
+		return response.Change{}, err
+	}
+
+	change := response.Change{
+		Base:    start.Base,
+		StartAt: start.Date,
+		EndAt:   end.Date,
+		Rates:   make(map[string]response.RateChange),
+	}
+
+	for code, startRate := range start.Rates {
+		endRate, ok := end.Rates[code]
+		if !ok {
+			continue
+		}
+
+		change.Rates[code] = response.RateChange{
+			Start:    startRate,
+			End:      endRate,
+			Absolute: endRate - startRate,
+			Percent:  (endRate - startRate) / startRate * 100,
+		}
+	}
+
+	for _, code := range f.against {
+		if _, ok := change.Rates[sanitizeCurrencyCode(code)]; !ok {
+			return response.Change{}, fmt.Errorf("%w: %v", ErrCurrencyNotFound, code)
+		}
+	}
+
+	return change, nil
+}
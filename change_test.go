@@ -0,0 +1,131 @@
+package gexc
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	time2 "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"testing"
+	"time"
+)
+
+//dateTestClient serves the tables of the exact requested dates
+type dateTestClient map[string]types.RateItem
+
+func (d dateTestClient) Latest(params openex.LatestParams) (*response.SingleDate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (d dateTestClient) SingleDate(params openex.SingleDateParams) (*response.SingleDate, error) {
+	rates, ok := d[params.Date.String()]
+	if !ok {
+		return nil, errors.New("unexpected date: " + params.Date.String())
+	}
+
+	return &response.SingleDate{Base: params.Base, Rates: rates, Date: params.Date}, nil
+}
+
+func (d dateTestClient) History(params openex.HistoryParams) (*response.History, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestFx_Change(t *testing.T) {
+	client := dateTestClient{
+		"2020-12-01": {"TRY": 9.5, "USD": 1.2},
+		"2020-12-24": {"TRY": 9.0, "USD": 1.22},
+	}
+
+	tests := []struct {
+		name        string
+		against     []string
+		from        time.Time
+		to          time.Time
+		calendar    time2.Calendar
+		wantStartAt string
+		wantEndAt   string
+		want        map[string]response.RateChange
+		wantErr     error
+	}{
+		{
+			name:        "should resolve holidays to previous publication day",
+			against:     []string{"try", "USD"},
+			from:        time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			to:          time.Date(2020, 12, 27, 0, 0, 0, 0, time.UTC),
+			wantStartAt: "2020-12-01",
+			wantEndAt:   "2020-12-24",
+			want: map[string]response.RateChange{
+				"TRY": {Start: 9.5, End: 9.0, Absolute: -0.5, Percent: -0.5 / 9.5 * 100},
+				"USD": {Start: 1.2, End: 1.22, Absolute: 0.02, Percent: 0.02 / 1.2 * 100},
+			},
+		},
+		{
+			name:     "should use the given calendar",
+			against:  []string{"TRY"},
+			from:     time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC),
+			calendar: time2.WeekdayCalendar{},
+			wantErr:  ErrClientFailed,
+		},
+		{
+			name:     "should raise an error if the calendar never publishes",
+			against:  []string{"TRY"},
+			from:     time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC),
+			calendar: time2.CalendarFunc(func(t time.Time) bool { return false }),
+			wantErr:  time2.ErrNoPublicationDay,
+		},
+		{
+			name:    "should raise an error if to is before from",
+			from:    time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "should raise an error if to is in the future",
+			from:    time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2020, 12, 30, 0, 0, 0, 0, time.UTC),
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "should raise an error if currency does not exist in rates",
+			against: []string{"GBP"},
+			from:    time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC),
+			wantErr: ErrCurrencyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time2.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC))
+			f := newFxWithClient(client, WithCalendar(tt.calendar), WithClock(clock))
+
+			got, err := f.BasedOn("EUR").Against(tt.against...).Change(tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Change() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.StartAt.String() != tt.wantStartAt || got.EndAt.String() != tt.wantEndAt || got.Base != "EUR" {
+				t.Errorf("Change() got = %v %v - %v", got.Base, got.StartAt, got.EndAt)
+			}
+
+			if len(got.Rates) != len(tt.want) {
+				t.Errorf("Change() rates = %v, want %v", got.Rates, tt.want)
+			}
+
+			for code, want := range tt.want {
+				rate := got.Rates[code]
+				if rate.Start != want.Start || rate.End != want.End ||
+					math.Abs(rate.Absolute-want.Absolute) > 1e-9 || math.Abs(rate.Percent-want.Percent) > 1e-9 {
+					t.Errorf("Change() rates of %v = %v, want %v", code, rate, want)
+				}
+			}
+		})
+	}
+}
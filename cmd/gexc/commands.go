@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/fufuceng/gexc"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type conversionJSON struct {
+	Amount        float64 `json:"amount"`
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Rate          float64 `json:"rate"`
+	Result        float64 `json:"result"`
+	Date          string  `json:"date"`
+	RequestedDate string  `json:"requested_date,omitempty"`
+}
+
+type ratesJSON struct {
+	Base  string         `json:"base"`
+	Date  string         `json:"date"`
+	Rates types.RateItem `json:"rates"`
+}
+
+type historyJSON struct {
+	Base    string             `json:"base"`
+	StartAt string             `json:"start_at"`
+	EndAt   string             `json:"end_at"`
+	Rates   types.TimeRateItem `json:"rates"`
+}
+
+type currencyJSON struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func runConvert(args []string, stdout, stderr io.Writer) error {
+	fs, cf := newFlagSet("convert", stderr)
+	date := fs.String("date", "", "date of the rates as YYYY-MM-DD, default is the latest rates")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 3 {
+		return usagef("expected arguments AMOUNT FROM TO, got %v", strings.Join(positional, " "))
+	}
+
+	amount, err := strconv.ParseFloat(positional[0], 64)
+	if err != nil {
+		return usagef("invalid amount %q", positional[0])
+	}
+
+	cfg, fx, err := setup(cf)
+	if err != nil {
+		return err
+	}
+
+	from := fx.Amount(amount).From(positional[1])
+
+	conversion := from.Latest()
+	if *date != "" {
+		t, err := parseDate("date", *date)
+		if err != nil {
+			return err
+		}
+
+		conversion = from.At(t)
+	}
+
+	result, err := conversion.To(positional[2])
+	if err != nil {
+		return err
+	}
+
+	value := conversionJSON{
+		Amount: result.Amount,
+		From:   result.From,
+		To:     result.To,
+		Rate:   result.Rate,
+		Result: result.Result,
+		Date:   result.Date.String(),
+	}
+
+	if !result.RequestedDate.IsZero() {
+		value.RequestedDate = result.RequestedDate.String()
+	}
+
+	return write(stdout, cfg.Format, output{
+		header: []string{"AMOUNT", "FROM", "TO", "RATE", "RESULT", "DATE"},
+		rows: [][]string{{
+			formatFloat(value.Amount), value.From, value.To,
+			formatFloat(value.Rate), formatFloat(value.Result), value.Date,
+		}},
+		value: value,
+	})
+}
+
+func runLatest(args []string, stdout, stderr io.Writer) error {
+	fs, cf := newFlagSet("latest", stderr)
+	base := fs.String("base", "EUR", "base currency")
+	symbols := fs.String("symbols", "", "comma separated currencies, default is all")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, fx, err := setup(cf)
+	if err != nil {
+		return err
+	}
+
+	rates, err := fx.BasedOn(*base).Against(splitSymbols(*symbols)...).Latest()
+	if err != nil {
+		return err
+	}
+
+	out := output{
+		header: []string{"DATE", "BASE", "CURRENCY", "RATE"},
+		value:  ratesJSON{Base: rates.Base, Date: rates.Date.String(), Rates: rates.Rates},
+	}
+
+	for _, code := range sortedCodes(rates.Rates) {
+		out.rows = append(out.rows, []string{rates.Date.String(), rates.Base, code, formatFloat(rates.Rates[code])})
+	}
+
+	return write(stdout, cfg.Format, out)
+}
+
+func runHistory(args []string, stdout, stderr io.Writer) error {
+	fs, cf := newFlagSet("history", stderr)
+	base := fs.String("base", "EUR", "base currency")
+	symbols := fs.String("symbols", "", "comma separated currencies, default is all")
+	fromFlag := fs.String("from", "", "first date of the range as YYYY-MM-DD")
+	toFlag := fs.String("to", "", "last date of the range as YYYY-MM-DD")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	from, err := parseDate("from", *fromFlag)
+	if err != nil {
+		return err
+	}
+
+	to, err := parseDate("to", *toFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, fx, err := setup(cf)
+	if err != nil {
+		return err
+	}
+
+	history, err := fx.BasedOn(*base).Against(splitSymbols(*symbols)...).From(from).Until(to)
+	if err != nil {
+		return err
+	}
+
+	out := output{
+		header: []string{"DATE", "BASE", "CURRENCY", "RATE"},
+		value: historyJSON{
+			Base:    history.Base,
+			StartAt: history.StartAt.String(),
+			EndAt:   history.EndAt.String(),
+			Rates:   history.Rates,
+		},
+	}
+
+	dates := make([]string, 0, len(history.Rates))
+	for date := range history.Rates {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		rates := history.Rates[date]
+		for _, code := range sortedCodes(rates) {
+			out.rows = append(out.rows, []string{date, history.Base, code, formatFloat(rates[code])})
+		}
+	}
+
+	return write(stdout, cfg.Format, out)
+}
+
+func runCurrencies(args []string, stdout, stderr io.Writer) error {
+	fs, cf := newFlagSet("currencies", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := cf.resolve()
+	if err != nil {
+		return err
+	}
+
+	var values []currencyJSON
+	out := output{header: []string{"CODE", "NAME"}}
+	for _, currency := range gexc.Currencies() {
+		values = append(values, currencyJSON{Code: currency.Code, Name: currency.Name})
+		out.rows = append(out.rows, []string{currency.Code, currency.Name})
+	}
+
+	out.value = values
+	return write(stdout, cfg.Format, out)
+}
+
+//setup resolves the config and creates Fx
+func setup(cf *configFlags) (config, *gexc.Fx, error) {
+	cfg, err := cf.resolve()
+	if err != nil {
+		return config{}, nil, err
+	}
+
+	fx, err := newFx(cfg)
+	if err != nil {
+		return config{}, nil, err
+	}
+
+	return cfg, fx, nil
+}
+
+//parse parses the flags that may be given before,
+//between or after the positional arguments
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+
+			return nil, usagef("%v", err)
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+//parseFlags parses the flags of the commands without positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) > 0 {
+		return usagef("unexpected arguments %v", strings.Join(positional, " "))
+	}
+
+	return nil
+}
+
+func parseDate(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, usagef("--%v is required", name)
+	}
+
+	t, err := time.Parse(gtime.GexcLayout, value)
+	if err != nil {
+		return time.Time{}, usagef("invalid --%v %q, expected YYYY-MM-DD", name, value)
+	}
+
+	return t, nil
+}
+
+func splitSymbols(value string) []string {
+	var symbols []string
+	for _, symbol := range strings.Split(value, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+func sortedCodes(rates types.RateItem) []string {
+	codes := make([]string, 0, len(rates))
+	for code := range rates {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/ratefile"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultProvider = "exchangeratesapi"
+	fileProvider    = "file"
+)
+
+//config is the configuration that is shared by the commands
+type config struct {
+	Provider  string `json:"provider"`
+	Endpoint  string `json:"endpoint"`
+	AccessKey string `json:"access_key"`
+	Format    string `json:"format"`
+	RatesFile string `json:"rates_file"`
+}
+
+//configFlags registers the shared flags on a flag set and
+//resolves them with the environment and the config file
+type configFlags struct {
+	fs    *flag.FlagSet
+	path  string
+	flags config
+}
+
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *configFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	c := &configFlags{fs: fs}
+
+	fs.StringVar(&c.path, "config", "", "path of the JSON config file (env GEXC_CONFIG)")
+	fs.StringVar(&c.flags.Provider, "provider", "", "rate provider: "+defaultProvider+" or "+fileProvider+" (env GEXC_PROVIDER)")
+	fs.StringVar(&c.flags.Endpoint, "endpoint", "", "base url of the provider api (env GEXC_ENDPOINT)")
+	fs.StringVar(&c.flags.AccessKey, "access-key", "", "access key of the provider api (env GEXC_ACCESS_KEY)")
+	fs.StringVar(&c.flags.RatesFile, "rates-file", "", "CSV or JSON rate file of the file provider (env GEXC_RATES_FILE)")
+	fs.StringVar(&c.flags.Format, "format", "", "output format: table, json or csv (env GEXC_FORMAT)")
+
+	return fs, c
+}
+
+//resolve merges the flags, the environment variables,
+//the config file and the defaults, in order
+func (c *configFlags) resolve() (config, error) {
+	file, err := readConfigFile(first(c.path, os.Getenv("GEXC_CONFIG")))
+	if err != nil {
+		return config{}, err
+	}
+
+	cfg := config{
+		Provider:  first(c.flags.Provider, os.Getenv("GEXC_PROVIDER"), file.Provider, defaultProvider),
+		Endpoint:  first(c.flags.Endpoint, os.Getenv("GEXC_ENDPOINT"), file.Endpoint),
+		AccessKey: first(c.flags.AccessKey, os.Getenv("GEXC_ACCESS_KEY"), file.AccessKey),
+		Format:    first(c.flags.Format, os.Getenv("GEXC_FORMAT"), file.Format, formatTable),
+		RatesFile: first(c.flags.RatesFile, os.Getenv("GEXC_RATES_FILE"), file.RatesFile),
+	}
+
+	cfg.Provider = strings.ToLower(cfg.Provider)
+	cfg.Format = strings.ToLower(cfg.Format)
+
+	if _, ok := formats[cfg.Format]; !ok {
+		return config{}, usagef("unknown format %q", cfg.Format)
+	}
+
+	return cfg, nil
+}
+
+//readConfigFile reads the config file of path. Missing default
+//config file is ignored, an empty path means the default one.
+func readConfigFile(path string) (config, error) {
+	var cfg config
+
+	explicit := path != ""
+	if !explicit {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return cfg, nil
+		}
+
+		path = filepath.Join(dir, "gexc", "config.json")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return cfg, nil
+		}
+
+		return cfg, fmt.Errorf("reading config file: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+
+	return cfg, nil
+}
+
+//newFx creates Fx with the provider of the config
+func newFx(cfg config) (*gexc.Fx, error) {
+	switch cfg.Provider {
+	case defaultProvider:
+		var options []gexc.Option
+		if cfg.Endpoint != "" {
+			endpoint, err := gexc.ParseEndpoint(cfg.Endpoint)
+			if err != nil {
+				return nil, usagef("%v", err)
+			}
+
+			options = append(options, endpoint)
+		}
+
+		if cfg.AccessKey != "" {
+			options = append(options, gexc.WithAccessKey(cfg.AccessKey))
+		}
+
+		return gexc.New(options...), nil
+	case fileProvider:
+		if cfg.RatesFile == "" {
+			return nil, usagef("--rates-file is required for the %v provider", fileProvider)
+		}
+
+		provider, err := ratefile.Open(cfg.RatesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return gexc.New(gexc.WithProvider(provider)), nil
+	default:
+		return nil, usagef("unknown provider %q", cfg.Provider)
+	}
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
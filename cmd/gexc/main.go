@@ -0,0 +1,106 @@
+//Command gexc converts currencies and looks up the rates from the terminal.
+//
+//	gexc convert 100 EUR TRY [--date 2020-12-24]
+//	gexc latest --base EUR --symbols TRY,USD
+//	gexc history --base EUR --symbols TRY --from 2020-12-01 --to 2020-12-31
+//	gexc currencies
+//	gexc serve --addr :8080 [--no-cache]
+//	gexc watch --pairs EUR/TRY,EUR/USD --interval 1m --threshold 0.5% [--exec CMD] [--exit]
+//
+//Output is a table by default, --format json and --format csv are supported.
+//Provider, endpoint, access key and rate file are read from the flags, the
+//environment variables GEXC_PROVIDER, GEXC_ENDPOINT, GEXC_ACCESS_KEY,
+//GEXC_RATES_FILE and GEXC_FORMAT or
+//a JSON config file, in order. The config file is given by --config or
+//GEXC_CONFIG, default is gexc/config.json in the user config directory.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: gexc <command> [flags]
+
+Commands:
+  convert      convert an amount, e.g. convert 100 EUR TRY [--date 2020-12-24]
+  latest       latest rates, e.g. latest --base EUR --symbols TRY,USD
+  history      rates of a date range, e.g. history --from 2020-12-01 --to 2020-12-31
+  currencies   supported currencies
+  serve        serve the rates over HTTP, e.g. serve --addr :8080
+  watch        poll pairs and alert on moves, e.g. watch --pairs EUR/TRY --threshold 0.5%
+
+Run 'gexc <command> --help' for the flags of a command.
+`
+
+//command runs a subcommand with its arguments.
+//Results are written to stdout, flag errors and usage to stderr.
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"convert":    runConvert,
+	"latest":     runLatest,
+	"history":    runHistory,
+	"currencies": runCurrencies,
+	"watch":      runWatch,
+	"serve":      runServe,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+//run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "gexc: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := cmd(args[1:], stdout, stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+
+		fmt.Fprintf(stderr, "gexc %v: %v\n", args[0], err)
+
+		if errors.Is(err, errThreshold) {
+			return exitThreshold
+		}
+
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			return 2
+		}
+
+		return 1
+	}
+
+	return 0
+}
+
+//usageError is returned for invalid command lines
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
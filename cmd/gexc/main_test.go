@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"github.com/fufuceng/gexc/gexctest"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+//testEnv starts a fake api and isolates the test
+//from the environment and the config file of the user
+func testEnv(t *testing.T) string {
+	provider := gexctest.NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": 8, "USD": 1.25}).
+		SetRates(time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), types.RateItem{"TRY": 9, "USD": 1.2}).
+		SetClock(gtime.NewManualClock(time.Date(2020, 12, 29, 18, 0, 0, 0, time.UTC)))
+
+	server := gexctest.NewServer(provider)
+	t.Cleanup(server.Close)
+
+	for _, name := range []string{"GEXC_CONFIG", "GEXC_PROVIDER", "GEXC_ENDPOINT", "GEXC_ACCESS_KEY", "GEXC_FORMAT", "GEXC_RATES_FILE"} {
+		setenv(t, name, "")
+	}
+
+	setenv(t, "XDG_CONFIG_HOME", t.TempDir())
+	setenv(t, "HOME", t.TempDir())
+
+	return server.URL
+}
+
+func setenv(t *testing.T, name, value string) {
+	old, ok := os.LookupEnv(name)
+	_ = os.Setenv(name, value)
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(name, old)
+		} else {
+			_ = os.Unsetenv(name)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	endpoint := testEnv(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantCode int
+	}{
+		{
+			name: "should convert with the rates of the date",
+			args: []string{"convert", "100", "EUR", "TRY", "--date", "2020-12-28", "--endpoint", endpoint},
+			want: "AMOUNT  FROM  TO   RATE  RESULT  DATE\n" +
+				"100     EUR   TRY  9     900     2020-12-28\n",
+		},
+		{
+			name: "should print the latest rates as csv",
+			args: []string{"latest", "--symbols", "TRY,USD", "--format", "csv", "--endpoint", endpoint},
+			want: "DATE,BASE,CURRENCY,RATE\n2020-12-29,EUR,TRY,8\n2020-12-29,EUR,USD,1.25\n",
+		},
+		{
+			name: "should print the history as json",
+			args: []string{"history", "--base", "USD", "--symbols", "EUR", "--from", "2020-12-24",
+				"--to", "2020-12-28", "--format", "json", "--endpoint", endpoint},
+			want: `{
+  "base": "USD",
+  "start_at": "2020-12-24",
+  "end_at": "2020-12-28",
+  "rates": {
+    "2020-12-24": {
+      "EUR": 0.8
+    },
+    "2020-12-28": {
+      "EUR": 0.8333333333333334
+    }
+  }
+}
+`,
+		},
+		{
+			name:     "should exit with 2 for missing arguments",
+			args:     []string{"convert", "100", "EUR"},
+			wantCode: 2,
+		},
+		{
+			name:     "should exit with 2 for missing dates",
+			args:     []string{"history", "--from", "2020-12-24"},
+			wantCode: 2,
+		},
+		{
+			name:     "should exit with 2 for invalid endpoints",
+			args:     []string{"latest", "--endpoint", "localhost:8080"},
+			wantCode: 2,
+		},
+		{
+			name:     "should exit with 2 for unknown commands",
+			args:     []string{"rates"},
+			wantCode: 2,
+		},
+		{
+			name:     "should exit with 1 for failed requests",
+			args:     []string{"latest", "--base", "GBP", "--endpoint", endpoint},
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("run() code = %v, want %v, stderr = %v", code, tt.wantCode, stderr.String())
+			}
+
+			if tt.wantCode == 0 && stdout.String() != tt.want {
+				t.Errorf("run() output =\n%v\nwant\n%v", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_FileProvider(t *testing.T) {
+	testEnv(t)
+
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	if err := ioutil.WriteFile(path, []byte("date,TRY,USD\n2020-12-24,9.1,1.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"convert", "12", "USD", "TRY", "--provider", "file", "--rates-file", path, "--format", "csv"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("run() code = %v, stderr = %v", code, stderr.String())
+	}
+
+	want := "AMOUNT,FROM,TO,RATE,RESULT,DATE\n12,USD,TRY,7.583333333333333,91,2020-12-24\n"
+	if stdout.String() != want {
+		t.Errorf("run() output = %v, want %v", stdout.String(), want)
+	}
+
+	if code := run([]string{"latest", "--provider", "file"}, &stdout, &stderr); code != 2 {
+		t.Errorf("run() code = %v, want 2 without rate file", code)
+	}
+}
+
+func TestRun_Currencies(t *testing.T) {
+	testEnv(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"currencies"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() code = %v, stderr = %v", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if lines[0] != "CODE  NAME" || !strings.HasPrefix(lines[1], "AUD   australian dollar") {
+		t.Errorf("run() output = %v", stdout.String())
+	}
+}
+
+func TestConfigFlags_resolve(t *testing.T) {
+	testEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := ioutil.WriteFile(path, []byte(`{"endpoint": "http://file", "access_key": "file", "format": "csv"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setenv(t, "GEXC_CONFIG", path)
+	setenv(t, "GEXC_ACCESS_KEY", "env")
+
+	fs, cf := newFlagSet("test", ioutil.Discard)
+	if err := fs.Parse([]string{"--format", "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cf.resolve()
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+
+	want := config{Provider: defaultProvider, Endpoint: "http://file", AccessKey: "env", Format: formatJSON}
+	if got != want {
+		t.Errorf("resolve() got = %v, want %v", got, want)
+	}
+
+	setenv(t, "GEXC_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := cf.resolve(); err == nil {
+		t.Errorf("resolve() error = nil for missing config file")
+	}
+}
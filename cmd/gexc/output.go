@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+//output is the result of a command. Table and csv formats
+//print header and rows, json format prints value.
+type output struct {
+	header []string
+	rows   [][]string
+	value  interface{}
+}
+
+type writer func(w io.Writer, out output) error
+
+var formats = map[string]writer{
+	formatTable: writeTable,
+	formatJSON:  writeJSON,
+	formatCSV:   writeCSV,
+}
+
+func write(w io.Writer, format string, out output) error {
+	return formats[format](w, out)
+}
+
+func writeTable(w io.Writer, out output) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, strings.Join(out.header, "\t"))
+	for _, row := range out.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, out output) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(out.value)
+}
+
+func writeCSV(w io.Writer, out output) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(out.header); err != nil {
+		return err
+	}
+
+	if err := cw.WriteAll(out.rows); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
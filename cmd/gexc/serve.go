@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/server"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+//shutdownTimeout is the time that the running requests
+//are given to finish after an interrupt
+const shutdownTimeout = 5 * time.Second
+
+//readHeaderTimeout limits how long the clients can take to send the headers
+const readHeaderTimeout = 10 * time.Second
+
+func runServe(args []string, stdout, stderr io.Writer) error {
+	fs, cf := newFlagSet("serve", stderr)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	noCache := fs.Bool("no-cache", false, "fetch every request from the provider")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	_, fx, err := setup(cf)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	fmt.Fprintf(stderr, "gexc serve: listening on %v\n", listener.Addr())
+	return serve(ctx, listener, handler(fx, !*noCache))
+}
+
+//handler serves the rates of the provider of fx
+func handler(fx *gexc.Fx, cache bool) http.Handler {
+	provider := fx.Provider()
+	if cache {
+		provider = server.NewCache(provider, server.WithClock(fx.Clock()))
+	}
+
+	return server.New(provider)
+}
+
+//serve serves the handler until the context is cancelled
+func serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: readHeaderTimeout}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"github.com/fufuceng/gexc"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	endpoint := testEnv(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, listener, handler(gexc.New(gexc.WithEndpoint(endpoint)), true))
+	}()
+
+	//gexc is the client of its own mirror
+	mirror := gexc.New(gexc.WithEndpoint("http://" + listener.Addr().String()))
+
+	conversion, err := mirror.ConvertAt(100, "EUR", "TRY", time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC))
+	if err != nil || conversion.Result != 900 {
+		t.Errorf("ConvertAt() got = %v, error = %v", conversion, err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve() error = %v", err)
+	}
+}
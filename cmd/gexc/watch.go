@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	gtime "github.com/fufuceng/gexc/time"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+//exitThreshold is the exit code of watch when --exit is given
+//and a threshold is crossed
+const exitThreshold = 3
+
+var errThreshold = errors.New("threshold crossed")
+
+//pair is a watched currency pair, e.g. EUR/TRY
+type pair struct {
+	base  string
+	quote string
+}
+
+func (p pair) String() string {
+	return p.base + "/" + p.quote
+}
+
+//threshold is the change since open that raises an alert, see watcher.
+//Percent thresholds are given like 0.5%, others are absolute.
+type threshold struct {
+	value   float64
+	percent bool
+}
+
+func (t threshold) crossed(change, percent float64) bool {
+	if t.value == 0 {
+		return false
+	}
+
+	if t.percent {
+		return math.Abs(percent) >= t.value
+	}
+
+	return math.Abs(change) >= t.value
+}
+
+//tick is the state of a pair at a refresh
+type tick struct {
+	Time          time.Time `json:"time"`
+	Pair          string    `json:"pair"`
+	Rate          float64   `json:"rate"`
+	Open          float64   `json:"open"`
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"change_percent"`
+	TickChange    float64   `json:"tick_change"`
+	Alert         bool      `json:"alert"`
+}
+
+//watcher polls the latest rates of the pairs. Open rate of a pair is
+//its rate on the publication day before the latest rates, so the change
+//since open is the move of the latest publication.
+type watcher struct {
+	fx          *gexc.Fx
+	pairs       []pair
+	interval    time.Duration
+	threshold   threshold
+	command     string
+	exitOnAlert bool
+	count       int
+	format      string
+	color       bool
+	stdout      io.Writer
+	stderr      io.Writer
+	//sleep waits for the next refresh, it is replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+
+	//opened is the date of the latest rates that
+	//the open rates of a base are seeded for
+	opened  map[string]time.Time
+	open    map[pair]float64
+	last    map[pair]float64
+	alerted map[pair]bool
+	csv     *csv.Writer
+}
+
+func runWatch(args []string, stdout, stderr io.Writer) error {
+	fs, cf := newFlagSet("watch", stderr)
+	pairs := fs.String("pairs", "", "comma separated pairs to watch, e.g. EUR/TRY,EUR/USD")
+	interval := fs.Duration("interval", time.Minute, "time between the refreshes")
+	thresholdFlag := fs.String("threshold", "", "change since the previous publication day that raises an alert, e.g. 0.05 or 0.5%")
+	command := fs.String("exec", "", "shell command that is run when a threshold is crossed")
+	exitOnAlert := fs.Bool("exit", false, fmt.Sprintf("exit with code %d when a threshold is crossed", exitThreshold))
+	count := fs.Int("count", 0, "number of refreshes, 0 means until interrupted")
+	color := fs.Bool("color", isTerminal(stdout), "highlight the moves in table format")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	watched, err := parsePairs(*pairs)
+	if err != nil {
+		return err
+	}
+
+	limit, err := parseThreshold(*thresholdFlag)
+	if err != nil {
+		return err
+	}
+
+	if *interval <= 0 {
+		return usagef("--interval should be positive")
+	}
+
+	cfg, fx, err := setup(cf)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	w := &watcher{
+		fx:          fx,
+		pairs:       watched,
+		interval:    *interval,
+		threshold:   limit,
+		command:     *command,
+		exitOnAlert: *exitOnAlert,
+		count:       *count,
+		format:      cfg.Format,
+		color:       *color,
+		stdout:      stdout,
+		stderr:      stderr,
+		sleep:       sleep,
+	}
+
+	return w.run(ctx)
+}
+
+//run refreshes the pairs until the context is cancelled, the count
+//is reached or a threshold is crossed with exitOnAlert
+func (w *watcher) run(ctx context.Context) error {
+	w.open = make(map[pair]float64)
+	w.opened = make(map[string]time.Time)
+	w.last = make(map[pair]float64)
+	w.alerted = make(map[pair]bool)
+
+	for n := 1; ; n++ {
+		ticks, err := w.refresh()
+		if err != nil {
+			//the first refresh fails for invalid settings,
+			//the later ones are retried
+			if n == 1 {
+				return err
+			}
+
+			fmt.Fprintf(w.stderr, "gexc watch: %v\n", err)
+		}
+
+		if err := w.write(ticks); err != nil {
+			return err
+		}
+
+		crossed := false
+		for _, t := range ticks {
+			if t.Alert {
+				crossed = true
+				w.alert(ctx, t)
+			}
+		}
+
+		if crossed && w.exitOnAlert {
+			return errThreshold
+		}
+
+		if w.count > 0 && n >= w.count {
+			return nil
+		}
+
+		if err := w.sleep(ctx, w.interval); err != nil {
+			return nil
+		}
+	}
+}
+
+//refresh fetches the latest rates of the pairs, grouped by base currency
+func (w *watcher) refresh() ([]tick, error) {
+	var bases []string
+	quotes := make(map[string][]string)
+	for _, p := range w.pairs {
+		if _, ok := quotes[p.base]; !ok {
+			bases = append(bases, p.base)
+		}
+
+		quotes[p.base] = append(quotes[p.base], p.quote)
+	}
+
+	rates := make(map[pair]float64)
+	for _, base := range bases {
+		latest, err := w.fx.BasedOn(base).Against(quotes[base]...).Latest()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, quote := range quotes[base] {
+			rate, ok := latest.Rates[quote]
+			if !ok {
+				return nil, fmt.Errorf("%w: %v", gexc.ErrCurrencyNotFound, quote)
+			}
+
+			rates[pair{base: base, quote: quote}] = rate
+		}
+
+		if date := latest.Date.Time; !date.Equal(w.opened[base]) {
+			if err := w.seedOpen(base, quotes[base], date); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	now := w.fx.Clock().Now()
+
+	ticks := make([]tick, 0, len(w.pairs))
+	for _, p := range w.pairs {
+		rate := rates[p]
+
+		open := w.open[p]
+		last, ok := w.last[p]
+		if !ok {
+			last = rate
+		}
+
+		w.last[p] = rate
+
+		t := tick{
+			Time:          now,
+			Pair:          p.String(),
+			Rate:          rate,
+			Open:          open,
+			Change:        rate - open,
+			ChangePercent: (rate - open) / open * 100,
+			TickChange:    rate - last,
+		}
+
+		//alerts are raised once, until the change goes back within the threshold
+		crossed := w.threshold.crossed(t.Change, t.ChangePercent)
+		t.Alert = crossed && !w.alerted[p]
+		w.alerted[p] = crossed
+
+		ticks = append(ticks, t)
+	}
+
+	return ticks, nil
+}
+
+//seedOpen sets the open rates of the base to its rates on the
+//publication day before the date of the latest rates
+func (w *watcher) seedOpen(base string, quotes []string, date time.Time) error {
+	previous, err := gtime.PreviousPublicationDay(w.fx.Calendar(), date)
+	if err != nil {
+		return err
+	}
+
+	rates, err := w.fx.BasedOn(base).Against(quotes...).At(previous)
+	if err != nil {
+		return fmt.Errorf("open rates of %v: %w", base, err)
+	}
+
+	for _, quote := range quotes {
+		rate, ok := rates.Rates[quote]
+		if !ok || rate == 0 {
+			return fmt.Errorf("%w: %v at %v", gexc.ErrCurrencyNotFound, quote, gtime.NewGexc(previous))
+		}
+
+		w.open[pair{base: base, quote: quote}] = rate
+	}
+
+	w.opened[base] = date
+	return nil
+}
+
+//alert runs the command of the alerts with the details of the tick
+//in its environment. Failures of the command do not stop the watch.
+func (w *watcher) alert(ctx context.Context, t tick) {
+	if w.command == "" {
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", w.command)
+	cmd.Stdout = w.stdout
+	cmd.Stderr = w.stderr
+	cmd.Env = append(os.Environ(),
+		"GEXC_PAIR="+t.Pair,
+		"GEXC_RATE="+formatFloat(t.Rate),
+		"GEXC_OPEN="+formatFloat(t.Open),
+		"GEXC_CHANGE="+formatFloat(t.Change),
+		"GEXC_CHANGE_PERCENT="+formatFloat(t.ChangePercent),
+	)
+
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(w.stderr, "gexc watch: alert command of %v failed: %v\n", t.Pair, err)
+	}
+}
+
+func (w *watcher) write(ticks []tick) error {
+	switch w.format {
+	case formatJSON:
+		//json lines, a line for each pair of each refresh
+		encoder := json.NewEncoder(w.stdout)
+		for _, t := range ticks {
+			if err := encoder.Encode(t); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case formatCSV:
+		if w.csv == nil {
+			w.csv = csv.NewWriter(w.stdout)
+			_ = w.csv.Write([]string{"TIME", "PAIR", "RATE", "OPEN", "CHANGE", "CHANGE%", "TICK", "ALERT"})
+		}
+
+		for _, t := range ticks {
+			_ = w.csv.Write([]string{
+				t.Time.Format(time.RFC3339), t.Pair, formatFloat(t.Rate), formatFloat(t.Open),
+				formatFloat(t.Change), formatFloat(t.ChangePercent), formatFloat(t.TickChange),
+				strconv.FormatBool(t.Alert),
+			})
+		}
+
+		w.csv.Flush()
+		return w.csv.Error()
+	default:
+		return w.writeTable(ticks)
+	}
+}
+
+func (w *watcher) writeTable(ticks []tick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%v\n", ticks[0].Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintln(tw, "PAIR\tRATE\tOPEN\tCHANGE\tCHANGE%\tTICK\t")
+
+	for _, t := range ticks {
+		marker := ""
+		if t.Alert {
+			marker = "!"
+		}
+
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			t.Pair, formatFloat(t.Rate), formatFloat(t.Open),
+			w.highlight(t.Change, "%+.4f"), w.highlight(t.ChangePercent, "%+.2f%%"),
+			w.highlight(t.TickChange, "%+.4f"), marker)
+	}
+
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
+
+//highlight formats the change, green for rises and red for falls
+//if color is enabled
+func (w *watcher) highlight(value float64, format string) string {
+	text := fmt.Sprintf(format, value)
+	if !w.color || value == 0 {
+		return text
+	}
+
+	code := "32"
+	if value < 0 {
+		code = "31"
+	}
+
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+func parsePairs(value string) ([]pair, error) {
+	var pairs []pair
+	for _, item := range splitSymbols(value) {
+		codes := strings.Split(strings.ToUpper(item), "/")
+		if len(codes) != 2 || codes[0] == "" || codes[1] == "" {
+			return nil, usagef("invalid pair %q, expected BASE/QUOTE", item)
+		}
+
+		pairs = append(pairs, pair{base: codes[0], quote: codes[1]})
+	}
+
+	if len(pairs) == 0 {
+		return nil, usagef("--pairs is required")
+	}
+
+	return pairs, nil
+}
+
+func parseThreshold(value string) (threshold, error) {
+	if value == "" {
+		return threshold{}, nil
+	}
+
+	t := threshold{percent: strings.HasSuffix(value, "%")}
+
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || number <= 0 {
+		return threshold{}, usagef("invalid threshold %q, expected a positive number or percent", value)
+	}
+
+	t.value = number
+	return t, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//interruptContext is cancelled when the process is interrupted
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/gexctest"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+//testWatcher serves the rates one by one at each refresh
+func testWatcher(rates []float64, options func(w *watcher)) (*watcher, *bytes.Buffer) {
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC))
+	provider := gexctest.NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": rates[0], "USD": 1.2}).
+		SetClock(clock)
+
+	var stdout bytes.Buffer
+	n := 0
+	w := &watcher{
+		fx:       gexctest.NewFx(provider, gexc.WithClock(clock)),
+		pairs:    []pair{{base: "EUR", quote: "TRY"}, {base: "EUR", quote: "USD"}},
+		interval: time.Minute,
+		count:    len(rates),
+		format:   formatJSON,
+		stdout:   &stdout,
+		stderr:   &stdout,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			n++
+			clock.Advance(d)
+			provider.SetStatic(types.RateItem{"TRY": rates[n], "USD": 1.2})
+			return nil
+		},
+	}
+
+	if options != nil {
+		options(w)
+	}
+
+	return w, &stdout
+}
+
+func decodeTicks(t *testing.T, out *bytes.Buffer) []tick {
+	var ticks []tick
+
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var tk tick
+		if err := decoder.Decode(&tk); err != nil {
+			t.Fatal(err)
+		}
+
+		if tk.Pair == "EUR/TRY" {
+			ticks = append(ticks, tk)
+		}
+	}
+
+	return ticks
+}
+
+func TestWatcher_run(t *testing.T) {
+	tests := []struct {
+		name       string
+		rates      []float64
+		threshold  threshold
+		exit       bool
+		wantAlerts []bool
+		wantErr    error
+	}{
+		{
+			name:       "should alert once when percent threshold is crossed",
+			rates:      []float64{8, 8.02, 8.1, 8.12, 8.01, 8.1},
+			threshold:  threshold{value: 1, percent: true},
+			wantAlerts: []bool{false, false, true, false, false, true},
+		},
+		{
+			name:       "should alert for falls with absolute threshold",
+			rates:      []float64{8, 7.9, 7.7},
+			threshold:  threshold{value: 0.25},
+			wantAlerts: []bool{false, false, true},
+		},
+		{
+			name:       "should stop when threshold is crossed with exit",
+			rates:      []float64{8, 8.5, 8.6},
+			threshold:  threshold{value: 0.25},
+			exit:       true,
+			wantAlerts: []bool{false, true},
+			wantErr:    errThreshold,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, out := testWatcher(tt.rates, func(w *watcher) {
+				w.threshold = tt.threshold
+				w.exitOnAlert = tt.exit
+			})
+
+			if err := w.run(context.Background()); err != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			ticks := decodeTicks(t, out)
+
+			var alerts []bool
+			for _, tk := range ticks {
+				alerts = append(alerts, tk.Alert)
+			}
+
+			if !reflect.DeepEqual(alerts, tt.wantAlerts) {
+				t.Errorf("run() alerts = %v, want %v", alerts, tt.wantAlerts)
+			}
+		})
+	}
+}
+
+func TestWatcher_changes(t *testing.T) {
+	w, out := testWatcher([]float64{8, 8.2, 8.1}, nil)
+	if err := w.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ticks := decodeTicks(t, out)
+	if len(ticks) != 3 {
+		t.Fatalf("run() ticks = %v", ticks)
+	}
+
+	last := ticks[2]
+	if last.Open != 8 || math.Abs(last.Change-0.1) > 1e-9 || math.Abs(last.ChangePercent-1.25) > 1e-9 ||
+		math.Abs(last.TickChange+0.1) > 1e-9 {
+		t.Errorf("run() last tick = %+v", last)
+	}
+
+	if !ticks[1].Time.Equal(ticks[0].Time.Add(time.Minute)) {
+		t.Errorf("run() tick times = %v, %v", ticks[0].Time, ticks[1].Time)
+	}
+}
+
+func TestWatcher_open(t *testing.T) {
+	//2020-12-24 is the publication day before 2020-12-28
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 14, 50, 0, 0, time.UTC))
+	provider := gexctest.NewProvider("EUR").
+		SetRates(time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC), types.RateItem{"TRY": 7.9}).
+		SetRates(time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), types.RateItem{"TRY": 8}).
+		SetClock(clock)
+
+	var stdout bytes.Buffer
+	w := &watcher{
+		fx:       gexctest.NewFx(provider, gexc.WithClock(clock)),
+		pairs:    []pair{{base: "EUR", quote: "TRY"}},
+		interval: 15 * time.Minute,
+		count:    2,
+		format:   formatJSON,
+		stdout:   &stdout,
+		stderr:   &stdout,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			clock.Advance(d)
+			provider.SetRates(time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC), types.RateItem{"TRY": 8.2})
+			return nil
+		},
+	}
+
+	if err := w.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ticks := decodeTicks(t, &stdout)
+	if len(ticks) != 2 {
+		t.Fatalf("run() ticks = %v", ticks)
+	}
+
+	//open is the rate of the previous publication day, not the first received rate
+	if ticks[0].Rate != 8 || ticks[0].Open != 7.9 || math.Abs(ticks[0].Change-0.1) > 1e-9 {
+		t.Errorf("run() first tick = %+v", ticks[0])
+	}
+
+	//rates of 2020-12-29 are published, so the rate of 2020-12-28 is the new open
+	if ticks[1].Rate != 8.2 || ticks[1].Open != 8 || math.Abs(ticks[1].Change-0.2) > 1e-9 {
+		t.Errorf("run() second tick = %+v", ticks[1])
+	}
+}
+
+func TestWatcher_exec(t *testing.T) {
+	w, out := testWatcher([]float64{8, 9}, func(w *watcher) {
+		w.threshold = threshold{value: 10, percent: true}
+		w.command = `echo "alert $GEXC_PAIR $GEXC_RATE"`
+		w.format = formatCSV
+	})
+
+	if err := w.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "alert EUR/TRY 9\n") {
+		t.Errorf("run() output = %v", out.String())
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    threshold
+		wantErr bool
+	}{
+		{value: "0.5%", want: threshold{value: 0.5, percent: true}},
+		{value: "0.05", want: threshold{value: 0.05}},
+		{value: "", want: threshold{}},
+		{value: "-1", wantErr: true},
+		{value: "abc%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseThreshold(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseThreshold() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Watch(t *testing.T) {
+	endpoint := testEnv(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"watch", "--pairs", "EUR/TRY", "--count", "1", "--color=false", "--endpoint", endpoint}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("run() code = %v, stderr = %v", code, stderr.String())
+	}
+
+	if !strings.Contains(stdout.String(), "EUR/TRY  8     9     -1.0000  -11.11%  +0.0000") {
+		t.Errorf("run() output =\n%v", stdout.String())
+	}
+
+	code = run([]string{"watch", "--pairs", "EURTRY", "--endpoint", endpoint}, &stdout, &stderr)
+	if code != 2 {
+		t.Errorf("run() code = %v, want 2", code)
+	}
+}
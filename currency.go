@@ -1,6 +1,9 @@
 package gexc
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 type Currency struct {
 	Code string
@@ -77,6 +80,16 @@ func CurrencyByName(name string) (Currency, bool) {
 	return currencyByX(&nameCurrencyMap, sanitizeCurrencyName(name))
 }
 
+//Currencies returns the supported currencies ordered by code
+func Currencies() []Currency {
+	currencies := append([]Currency(nil), supportedCurrencies...)
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+
+	return currencies
+}
+
 func currencyByX(source *map[string]*Currency, key string) (Currency, bool) {
 	if currency, ok := (*source)[key]; ok {
 		return *currency, true
@@ -194,3 +194,21 @@ func Test_currencyByX(t *testing.T) {
 		})
 	}
 }
+
+func TestCurrencies(t *testing.T) {
+	got := Currencies()
+	if len(got) != len(supportedCurrencies) {
+		t.Fatalf("Currencies() len = %v, want %v", len(got), len(supportedCurrencies))
+	}
+
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Code >= got[i].Code {
+			t.Errorf("Currencies() is not ordered by code: %v, %v", got[i-1].Code, got[i].Code)
+		}
+	}
+
+	got[0].Code = "XXX"
+	if Currencies()[0].Code == "XXX" {
+		t.Errorf("Currencies() should return a copy")
+	}
+}
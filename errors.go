@@ -1,6 +1,9 @@
 package gexc
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrUnsupportedCurrency = errors.New("unsupported currency")
@@ -8,3 +11,22 @@ var (
 	ErrInvalidParameter    = errors.New("invalid parameter")
 	ErrClientFailed        = errors.New("client failed")
 )
+
+//providerError is a failure of the provider. It matches ErrClientFailed
+//and unwraps to the error of the provider, so typed errors of custom
+//providers can be checked by errors.As.
+type providerError struct {
+	err error
+}
+
+func (e *providerError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrClientFailed, e.err)
+}
+
+func (e *providerError) Is(target error) bool {
+	return target == ErrClientFailed
+}
+
+func (e *providerError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,101 @@
+package gexc
+
+import (
+	"context"
+	"errors"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	"testing"
+	"time"
+)
+
+type testProviderError struct {
+	code int
+}
+
+func (e *testProviderError) Error() string {
+	return "provider failed"
+}
+
+//failingTestClient fails all requests with err
+type failingTestClient struct {
+	err error
+}
+
+func (f failingTestClient) Latest(params openex.LatestParams) (*response.SingleDate, error) {
+	return nil, f.err
+}
+
+func (f failingTestClient) SingleDate(params openex.SingleDateParams) (*response.SingleDate, error) {
+	return nil, f.err
+}
+
+func (f failingTestClient) History(params openex.HistoryParams) (*response.History, error) {
+	return nil, f.err
+}
+
+func TestFx_ProviderErrors(t *testing.T) {
+	f := newFxWithClient(failingTestClient{err: &testProviderError{code: 42}})
+	date := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "should wrap the errors of Latest",
+			call: func() error {
+				_, err := f.BasedOn("EUR").Against("TRY").Latest()
+				return err
+			},
+		},
+		{
+			name: "should wrap the errors of At",
+			call: func() error {
+				_, err := f.BasedOn("EUR").Against("TRY").At(date)
+				return err
+			},
+		},
+		{
+			name: "should wrap the errors of history windows",
+			call: func() error {
+				_, err := f.BasedOn("EUR").Against("TRY").From(date).Until(date.AddDate(0, 0, 7))
+				return err
+			},
+		},
+		{
+			name: "should wrap the errors of streamed history",
+			call: func() error {
+				it := f.BasedOn("EUR").Against("TRY").From(date).Stream(context.Background(), date.AddDate(0, 0, 7))
+				defer it.Close()
+
+				for it.Next() {
+				}
+
+				return it.Err()
+			},
+		},
+		{
+			name: "should wrap the errors of conversions",
+			call: func() error {
+				_, err := f.Convert(5, "EUR", "TRY")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			var providerErr *testProviderError
+			if !errors.Is(err, ErrClientFailed) || !errors.As(err, &providerErr) || providerErr.code != 42 {
+				t.Errorf("error = %v, want ErrClientFailed and *testProviderError", err)
+			}
+
+			if _, ok := err.(*HistoryError); !ok && err.Error() != "client failed: provider failed" {
+				t.Errorf("Error() = %q", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/csv"
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/types"
+	"io"
+	"math"
+	"time"
+)
+
+//CSVWriter writes the rates as CSV with a header record.
+//Missing rates of the wide layout are written as empty cells.
+type CSVWriter struct {
+	csv     *csv.Writer
+	encoder *encoder
+	header  bool
+}
+
+//NewCSVWriter creates a CSV writer of the layout
+func NewCSVWriter(w io.Writer, layout Layout, options ...Option) *CSVWriter {
+	return &CSVWriter{
+		csv:     csv.NewWriter(w),
+		encoder: newEncoder(layout, options),
+	}
+}
+
+//Write writes the rates of a date
+func (c *CSVWriter) Write(date time.Time, base string, rates types.RateItem) error {
+	if c.encoder.err != nil {
+		return c.encoder.err
+	}
+
+	records := c.encoder.records(date, base, rates)
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		values := make([]string, len(record))
+		for i, f := range record {
+			switch {
+			case f.isText:
+				values[i] = f.text
+			case !math.IsNaN(f.number):
+				values[i] = c.encoder.formatNumber(f.number)
+			}
+		}
+
+		if err := c.csv.Write(values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//WriteHistory writes the rates of the history in chronological order
+func (c *CSVWriter) WriteHistory(h response.History) error {
+	if c.encoder.err != nil {
+		return c.encoder.err
+	}
+
+	return c.encoder.history(h, c.Write)
+}
+
+//WriteSingleDate writes the rates of the result
+func (c *CSVWriter) WriteSingleDate(s response.SingleDate) error {
+	return c.Write(s.Date.Time, s.Base, s.Rates)
+}
+
+//Flush writes the buffered records to the underlying writer.
+//The header is written even if there are no records, unless
+//the currencies of the wide layout are unknown.
+func (c *CSVWriter) Flush() error {
+	if c.encoder.err != nil {
+		return c.encoder.err
+	}
+
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+func (c *CSVWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+
+	header := c.encoder.header()
+	if header == nil {
+		return nil
+	}
+
+	c.header = true
+	return c.csv.Write(header)
+}
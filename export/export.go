@@ -0,0 +1,246 @@
+//Package export writes history and single date results to spreadsheets
+//and data lakes. Results are streamed to an io.Writer as CSV or JSON Lines
+//in one of two layouts:
+//
+//	Long: a record for each rate with date, base, currency and rate columns
+//	Wide: a record for each date with date column and a column for each currency
+//
+//Writers accept whole results by WriteHistory and WriteSingleDate, or the
+//rates of a date at a time by Write, e.g. from gexc.HistoryIterator.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//Layout is the shape of the exported records
+type Layout int
+
+const (
+	//Long writes a record for each rate
+	Long Layout = iota
+	//Wide writes a record for each date with a column for each currency
+	Wide
+)
+
+//Column names of the fixed columns
+const (
+	ColumnDate     = "date"
+	ColumnBase     = "base"
+	ColumnCurrency = "currency"
+	ColumnRate     = "rate"
+)
+
+//ErrInvalidColumn is returned for unknown or duplicate columns
+var ErrInvalidColumn = errors.New("invalid column")
+
+var defaultColumns = map[Layout][]string{
+	Long: {ColumnDate, ColumnBase, ColumnCurrency, ColumnRate},
+	Wide: {ColumnDate},
+}
+
+//Option customizes the writers
+type Option func(o *options)
+
+type options struct {
+	dateFormat string
+	precision  int
+	columns    []string
+	currencies []string
+}
+
+//WithDateFormat sets the layout of the dates, default is gtime.GexcLayout
+func WithDateFormat(layout string) Option {
+	return func(o *options) {
+		o.dateFormat = layout
+	}
+}
+
+//WithPrecision sets the number of decimals of the rates.
+//Negative values write the shortest exact representation, which is the default.
+func WithPrecision(decimals int) Option {
+	return func(o *options) {
+		o.precision = decimals
+	}
+}
+
+//WithColumns sets which fixed columns are written and in which order.
+//Long layout accepts date, base, currency and rate, default is all of them.
+//Wide layout accepts date and base, default is date only.
+//Currency columns of the wide layout follow the fixed columns.
+func WithColumns(columns ...string) Option {
+	return func(o *options) {
+		o.columns = columns
+	}
+}
+
+//WithCurrencies sets which currencies are written and in which order.
+//Default is all currencies in alphabetical order. Wide layout takes
+//the currencies of the first written result if they are not set.
+func WithCurrencies(codes ...string) Option {
+	return func(o *options) {
+		o.currencies = codes
+	}
+}
+
+//field is a column of a record, missing values are NaN
+type field struct {
+	name   string
+	text   string
+	number float64
+	isText bool
+}
+
+//encoder builds the records of both CSV and JSON Lines writers
+type encoder struct {
+	layout  Layout
+	options options
+	err     error
+}
+
+func newEncoder(layout Layout, opts []Option) *encoder {
+	e := &encoder{
+		layout:  layout,
+		options: options{dateFormat: gtime.GexcLayout, precision: -1},
+	}
+
+	for _, option := range opts {
+		option(&e.options)
+	}
+
+	if e.options.columns == nil {
+		e.options.columns = defaultColumns[layout]
+	}
+
+	e.err = e.validate()
+	return e
+}
+
+func (e *encoder) validate() error {
+	allowed := make(map[string]bool)
+	for _, column := range defaultColumns[Long] {
+		allowed[column] = e.layout == Long || column == ColumnDate || column == ColumnBase
+	}
+
+	seen := make(map[string]bool)
+	for _, column := range e.options.columns {
+		if !allowed[column] || seen[column] {
+			return fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+		}
+
+		seen[column] = true
+	}
+
+	return nil
+}
+
+//header returns the names of the columns. Wide layout
+//needs the currencies, so it may return nil before the first record.
+func (e *encoder) header() []string {
+	header := append([]string(nil), e.options.columns...)
+	if e.layout == Wide {
+		if e.options.currencies == nil {
+			return nil
+		}
+
+		header = append(header, e.options.currencies...)
+	}
+
+	return header
+}
+
+//records returns the records of the rates of a date
+func (e *encoder) records(date time.Time, base string, rates types.RateItem) [][]field {
+	if e.layout == Wide && e.options.currencies == nil {
+		e.options.currencies = sortedCodes(rates)
+	}
+
+	if e.layout == Wide {
+		record := e.fixed(date, base, "", math.NaN())
+		for _, code := range e.options.currencies {
+			rate, ok := rates[code]
+			if !ok {
+				rate = math.NaN()
+			}
+
+			record = append(record, field{name: code, number: rate})
+		}
+
+		return [][]field{record}
+	}
+
+	codes := e.options.currencies
+	if codes == nil {
+		codes = sortedCodes(rates)
+	}
+
+	var records [][]field
+	for _, code := range codes {
+		if rate, ok := rates[code]; ok && !math.IsNaN(rate) {
+			records = append(records, e.fixed(date, base, code, rate))
+		}
+	}
+
+	return records
+}
+
+func (e *encoder) fixed(date time.Time, base, currency string, rate float64) []field {
+	record := make([]field, 0, len(e.options.columns))
+	for _, column := range e.options.columns {
+		switch column {
+		case ColumnDate:
+			record = append(record, field{name: column, text: date.Format(e.options.dateFormat), isText: true})
+		case ColumnBase:
+			record = append(record, field{name: column, text: base, isText: true})
+		case ColumnCurrency:
+			record = append(record, field{name: column, text: currency, isText: true})
+		case ColumnRate:
+			record = append(record, field{name: column, number: rate})
+		}
+	}
+
+	return record
+}
+
+func (e *encoder) formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', e.options.precision, 64)
+}
+
+//history calls write with the rates of each date in chronological order.
+//Wide layout takes the currencies of the whole history if they are not set.
+func (e *encoder) history(h response.History, write func(date time.Time, base string, rates types.RateItem) error) error {
+	series, err := h.Series()
+	if err != nil {
+		return err
+	}
+
+	if e.layout == Wide && e.options.currencies == nil {
+		e.options.currencies = series.Currencies()
+	}
+
+	for i, date := range series.Dates {
+		if err := write(date.Time, series.Base, series.RatesAt(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedCodes(rates types.RateItem) []string {
+	codes := make([]string, 0, len(rates))
+	for code := range rates {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+	return codes
+}
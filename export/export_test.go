@@ -0,0 +1,156 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"testing"
+	"time"
+)
+
+func testHistory() response.History {
+	return response.History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-12-29": {"TRY": 9.12345, "USD": 1.2},
+			"2020-12-28": {"TRY": 9.0},
+		},
+	}
+}
+
+//writer is the common interface of the writers in tests
+type writer interface {
+	WriteHistory(h response.History) error
+	WriteSingleDate(s response.SingleDate) error
+	Flush() error
+}
+
+func TestWriters_WriteHistory(t *testing.T) {
+	tests := []struct {
+		name      string
+		newWriter func(buf *bytes.Buffer) writer
+		want      string
+	}{
+		{
+			name: "should write long csv",
+			newWriter: func(buf *bytes.Buffer) writer {
+				return NewCSVWriter(buf, Long)
+			},
+			want: "date,base,currency,rate\n" +
+				"2020-12-28,EUR,TRY,9\n" +
+				"2020-12-29,EUR,TRY,9.12345\n" +
+				"2020-12-29,EUR,USD,1.2\n",
+		},
+		{
+			name: "should write wide csv with empty missing rates",
+			newWriter: func(buf *bytes.Buffer) writer {
+				return NewCSVWriter(buf, Wide)
+			},
+			want: "date,TRY,USD\n" +
+				"2020-12-28,9,\n" +
+				"2020-12-29,9.12345,1.2\n",
+		},
+		{
+			name: "should apply date format, precision and column order",
+			newWriter: func(buf *bytes.Buffer) writer {
+				return NewCSVWriter(buf, Long,
+					WithDateFormat("02.01.2006"),
+					WithPrecision(2),
+					WithColumns(ColumnCurrency, ColumnDate, ColumnRate),
+					WithCurrencies("USD", "TRY"))
+			},
+			want: "currency,date,rate\n" +
+				"TRY,28.12.2020,9.00\n" +
+				"USD,29.12.2020,1.20\n" +
+				"TRY,29.12.2020,9.12\n",
+		},
+		{
+			name: "should write wide csv with the given currencies",
+			newWriter: func(buf *bytes.Buffer) writer {
+				return NewCSVWriter(buf, Wide, WithColumns(ColumnBase, ColumnDate), WithCurrencies("USD"))
+			},
+			want: "base,date,USD\n" +
+				"EUR,2020-12-28,\n" +
+				"EUR,2020-12-29,1.2\n",
+		},
+		{
+			name: "should write long json lines",
+			newWriter: func(buf *bytes.Buffer) writer {
+				return NewJSONLinesWriter(buf, Long, WithColumns(ColumnDate, ColumnCurrency, ColumnRate))
+			},
+			want: `{"date":"2020-12-28","currency":"TRY","rate":9}` + "\n" +
+				`{"date":"2020-12-29","currency":"TRY","rate":9.12345}` + "\n" +
+				`{"date":"2020-12-29","currency":"USD","rate":1.2}` + "\n",
+		},
+		{
+			name: "should write wide json lines with null missing rates",
+			newWriter: func(buf *bytes.Buffer) writer {
+				return NewJSONLinesWriter(buf, Wide, WithPrecision(1))
+			},
+			want: `{"date":"2020-12-28","TRY":9.0,"USD":null}` + "\n" +
+				`{"date":"2020-12-29","TRY":9.1,"USD":1.2}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := tt.newWriter(&buf)
+
+			if err := w.WriteHistory(testHistory()); err != nil {
+				t.Fatalf("WriteHistory() error = %v", err)
+			}
+
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("WriteHistory() got =\n%v\nwant\n%v", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, Wide)
+
+	//currencies of the wide layout are taken from the first write
+	_ = w.Write(time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), "EUR", types.RateItem{"TRY": 9})
+	_ = w.WriteSingleDate(response.SingleDate{
+		Base:  "EUR",
+		Date:  gtime.NewGexc(time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC)),
+		Rates: types.RateItem{"TRY": 9.1, "USD": 1.2},
+	})
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "date,TRY\n2020-12-28,9\n2020-12-29,9.1\n"
+	if buf.String() != want {
+		t.Errorf("Write() got =\n%v\nwant\n%v", buf.String(), want)
+	}
+}
+
+func TestWriters_InvalidColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer writer
+	}{
+		{name: "unknown column", writer: NewCSVWriter(&bytes.Buffer{}, Long, WithColumns("price"))},
+		{name: "duplicate column", writer: NewCSVWriter(&bytes.Buffer{}, Long, WithColumns(ColumnDate, ColumnDate))},
+		{name: "rate column in wide layout", writer: NewJSONLinesWriter(&bytes.Buffer{}, Wide, WithColumns(ColumnRate))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.writer.WriteHistory(testHistory()); !errors.Is(err, ErrInvalidColumn) {
+				t.Errorf("WriteHistory() error = %v, want %v", err, ErrInvalidColumn)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/types"
+	"io"
+	"math"
+	"time"
+)
+
+//JSONLinesWriter writes a JSON object for each record, one per line.
+//Keys of the objects are in the column order and missing rates of
+//the wide layout are written as null.
+type JSONLinesWriter struct {
+	w       *bufio.Writer
+	encoder *encoder
+}
+
+//NewJSONLinesWriter creates a JSON Lines writer of the layout
+func NewJSONLinesWriter(w io.Writer, layout Layout, options ...Option) *JSONLinesWriter {
+	return &JSONLinesWriter{
+		w:       bufio.NewWriter(w),
+		encoder: newEncoder(layout, options),
+	}
+}
+
+//Write writes the rates of a date
+func (j *JSONLinesWriter) Write(date time.Time, base string, rates types.RateItem) error {
+	if j.encoder.err != nil {
+		return j.encoder.err
+	}
+
+	for _, record := range j.encoder.records(date, base, rates) {
+		if err := j.writeRecord(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//WriteHistory writes the rates of the history in chronological order
+func (j *JSONLinesWriter) WriteHistory(h response.History) error {
+	if j.encoder.err != nil {
+		return j.encoder.err
+	}
+
+	return j.encoder.history(h, j.Write)
+}
+
+//WriteSingleDate writes the rates of the result
+func (j *JSONLinesWriter) WriteSingleDate(s response.SingleDate) error {
+	return j.Write(s.Date.Time, s.Base, s.Rates)
+}
+
+//Flush writes the buffered records to the underlying writer
+func (j *JSONLinesWriter) Flush() error {
+	if j.encoder.err != nil {
+		return j.encoder.err
+	}
+
+	return j.w.Flush()
+}
+
+//writeRecord writes the fields by hand to keep the column order
+func (j *JSONLinesWriter) writeRecord(record []field) error {
+	_ = j.w.WriteByte('{')
+	for i, f := range record {
+		if i > 0 {
+			_ = j.w.WriteByte(',')
+		}
+
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return err
+		}
+
+		_, _ = j.w.Write(name)
+		_ = j.w.WriteByte(':')
+
+		switch {
+		case f.isText:
+			text, err := json.Marshal(f.text)
+			if err != nil {
+				return err
+			}
+
+			_, _ = j.w.Write(text)
+		case math.IsNaN(f.number) || math.IsInf(f.number, 0):
+			_, _ = j.w.WriteString("null")
+		default:
+			_, _ = j.w.WriteString(j.encoder.formatNumber(f.number))
+		}
+	}
+
+	_, err := j.w.WriteString("}\n")
+	return err
+}
@@ -31,7 +31,7 @@ func (f *fxToWrapper) To(currency string) (float64, error) {
 
 	resp, err := f.base.BasedOn(fromCurrency.Code).Against(toCurrency.Code).Latest()
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrClientFailed, err)
+		return 0, err
 	}
 
 	mul, ok := resp.Rates[toCurrency.Code]
@@ -42,6 +42,116 @@ func (f *fxToWrapper) To(currency string) (float64, error) {
 	return f.amount * mul, nil
 }
 
+//At is the optional third step of the currency conversion.
+//It takes the date whose rates will be used instead of the latest ones.
+func (f *fxToWrapper) At(t time.Time) *fxConversionWrapper {
+	return &fxConversionWrapper{
+		base:   f.base,
+		from:   f.from,
+		amount: f.amount,
+		date:   t,
+	}
+}
+
+//Latest is the optional third step of the currency conversion.
+//It uses the latest rates like To, but returns the details of the
+//conversion including the customer rate, see WithPricing.
+func (f *fxToWrapper) Latest() *fxConversionWrapper {
+	return &fxConversionWrapper{
+		base:   f.base,
+		from:   f.from,
+		amount: f.amount,
+		latest: true,
+	}
+}
+
+type fxConversionWrapper struct {
+	base   *Fx
+	from   string
+	amount float64
+	date   time.Time
+	latest bool
+}
+
+//To is the last step of the detailed currency conversion.
+//Rates are not published on weekends and holidays, the rates of the
+//previous published date are used for them. Date field of the result
+//tells which publication date is used.
+func (f *fxConversionWrapper) To(currency string) (response.Conversion, error) {
+	var requested time.Time
+	if !f.latest {
+		if f.date.Equal(time.Time{}) {
+			return response.Conversion{}, fmt.Errorf("%w: date should not be empty", ErrInvalidParameter)
+		}
+
+		requested = truncateDay(f.date)
+		if requested.After(f.base.now()) {
+			return response.Conversion{}, fmt.Errorf("%w: date should not be in the future", ErrInvalidParameter)
+		}
+	}
+
+	fromCurrency, ok := CurrencyByCode(f.from)
+	if !ok {
+		return response.Conversion{}, fmt.Errorf("%w: %v", ErrUnsupportedCurrency, f.from)
+	}
+
+	toCurrency, ok := CurrencyByCode(currency)
+	if !ok {
+		return response.Conversion{}, fmt.Errorf("%w: %v", ErrUnsupportedCurrency, currency)
+	}
+
+	rates := f.base.BasedOn(fromCurrency.Code).Against(toCurrency.Code)
+
+	var resp response.SingleDate
+	var err error
+	if f.latest {
+		resp, err = rates.Latest()
+	} else {
+		resp, err = rates.At(requested)
+	}
+
+	if err != nil {
+		return response.Conversion{}, err
+	}
+
+	mul, ok := resp.Rates[toCurrency.Code]
+	if !ok {
+		return response.Conversion{}, fmt.Errorf("%w: %v", ErrCurrencyNotFound, toCurrency.Code)
+	}
+
+	published := requested
+	if !resp.Date.IsZero() {
+		published = truncateDay(resp.Date.Time)
+	}
+
+	if !f.latest {
+		if err := checkPublished(published, requested); err != nil {
+			return response.Conversion{}, err
+		}
+	}
+
+	conversion := response.Conversion{
+		Amount: f.amount,
+		From:   fromCurrency.Code,
+		To:     toCurrency.Code,
+		Rate:   mul,
+	}
+
+	if !published.IsZero() {
+		conversion.Date = gtime.NewGexc(published)
+	}
+
+	if !f.latest {
+		conversion.RequestedDate = gtime.NewGexc(requested)
+	}
+
+	if err := f.base.price(&conversion); err != nil {
+		return response.Conversion{}, err
+	}
+
+	return conversion, nil
+}
+
 type fxFromWrapper struct {
 	base   *Fx
 	amount float64
@@ -66,42 +176,47 @@ type fxHistoryUntilWrapper struct {
 
 //Until is the last step of the history
 //It takes time value and validates all parameters then returns the history.
+//Long ranges are fetched in windows, see WithHistoryWindow. If some of the
+//windows fail, history of the others is returned with a *HistoryError.
 func (f *fxHistoryUntilWrapper) Until(t time.Time) (response.History, error) {
+	params, err := f.params(t)
+	if err != nil {
+		return response.History{}, err
+	}
+
+	return f.base.history(params)
+}
+
+func (f *fxHistoryUntilWrapper) params(t time.Time) (openex.HistoryParams, error) {
 	if f.from.Equal(time.Time{}) || t.Equal(time.Time{}) {
-		return response.History{}, fmt.Errorf("%w: time values should not be empty", ErrInvalidParameter)
+		return openex.HistoryParams{}, fmt.Errorf("%w: time values should not be empty", ErrInvalidParameter)
 	}
 
 	if !t.After(f.from) {
-		return response.History{}, fmt.Errorf("%w: until value should be bigger than from", ErrInvalidParameter)
+		return openex.HistoryParams{}, fmt.Errorf("%w: until value should be bigger than from", ErrInvalidParameter)
 	}
 
 	currency, ok := CurrencyByCode(f.currency)
 	if !ok {
-		return response.History{}, fmt.Errorf("%w: %v", ErrUnsupportedCurrency, f.currency)
+		return openex.HistoryParams{}, fmt.Errorf("%w: %v", ErrUnsupportedCurrency, f.currency)
 	}
 
 	var againstCurrencies []string
 	for _, code := range f.against {
 		cur, ok := CurrencyByCode(code)
 		if !ok {
-			return response.History{}, fmt.Errorf("%w: %v", ErrUnsupportedCurrency, code)
+			return openex.HistoryParams{}, fmt.Errorf("%w: %v", ErrUnsupportedCurrency, code)
 		}
 
 		againstCurrencies = append(againstCurrencies, cur.Code)
 	}
 
-	resp, err := f.base.openexClient.History(openex.HistoryParams{
+	return openex.HistoryParams{
 		StartAt: gtime.NewGexc(f.from),
 		EndAt:   gtime.NewGexc(t),
 		Base:    currency.Code,
 		Symbols: againstCurrencies,
-	})
-
-	if err != nil {
-		return response.History{}, fmt.Errorf("%w: %v", ErrClientFailed, err)
-	}
-
-	return *resp, nil
+	}, nil
 }
 
 type fxRatesFromWrapper struct {
@@ -144,12 +259,23 @@ func (f *fxRatesFromWrapper) Latest() (response.SingleDate, error) {
 	})
 
 	if err != nil {
-		return response.SingleDate{}, fmt.Errorf("%w: %v", ErrClientFailed, err)
+		return response.SingleDate{}, &providerError{err: err}
 	}
 
 	return *resp, nil
 }
 
+//checkPublished returns an error if the provider returned
+//the rates of a day after the requested one
+func checkPublished(published, requested time.Time) error {
+	if published.After(requested) {
+		return fmt.Errorf("%w: rates of %v returned for %v",
+			ErrClientFailed, gtime.NewGexc(published), gtime.NewGexc(requested))
+	}
+
+	return nil
+}
+
 //At calculates currency values corresponding to the given currency based on given date
 func (f *fxRatesFromWrapper) At(t time.Time) (response.SingleDate, error) {
 	curr, ok := CurrencyByCode(f.baseCurrency)
@@ -174,7 +300,7 @@ func (f *fxRatesFromWrapper) At(t time.Time) (response.SingleDate, error) {
 	})
 
 	if err != nil {
-		return response.SingleDate{}, fmt.Errorf("%w: %v", ErrClientFailed, err)
+		return response.SingleDate{}, &providerError{err: err}
 	}
 
 	return *resp, nil
@@ -198,7 +324,16 @@ func (f *fxRatesWrapper) Against(currencies ...string) *fxRatesFromWrapper {
 //Fx collects all functionality of the library
 //It includes Amount, Convert and BasedOn functions
 type Fx struct {
-	openexClient openex.Client
+	openexClient  openex.Client
+	concurrency   int
+	historyWindow int
+	calendar      gtime.Calendar
+	pricing       *Pricing
+	pricingErr    error
+	clientConfig  openex.Config
+	//clientErr is the error of the configuration of the default provider
+	clientErr error
+	clock     gtime.Clock
 }
 
 //Amount is the initial step of the currency conversion.
@@ -216,6 +351,12 @@ func (f *Fx) Convert(amount float64, from, to string) (float64, error) {
 	return f.Amount(amount).From(from).To(to)
 }
 
+//ConvertAt is the short form of `Amount.From.At.To` chain.
+//It converts the amount with the rates published at the given date
+func (f *Fx) ConvertAt(amount float64, from, to string, date time.Time) (response.Conversion, error) {
+	return f.Amount(amount).From(from).At(date).To(to)
+}
+
 //BasedOn is the initial step of the collection of the currency history
 //It takes base currency that will be compared to others in time range or specific time
 func (f *Fx) BasedOn(currency string) *fxRatesWrapper {
@@ -225,12 +366,35 @@ func (f *Fx) BasedOn(currency string) *fxRatesWrapper {
 	}
 }
 
-func New() *Fx {
-	return &Fx{
-		openexClient: openex.NewDefaultClient(),
+//New creates Fx with the default client.
+//Options customize the behaviour of the created Fx
+func New(options ...Option) *Fx {
+	f := &Fx{
+		concurrency:   defaultConcurrency,
+		historyWindow: defaultHistoryWindow,
+		clientConfig:  openex.DefaultConfig(),
+		clock:         gtime.SystemClock{},
 	}
+
+	for _, option := range options {
+		option(f)
+	}
+
+	switch {
+	case f.openexClient != nil:
+	case f.clientErr != nil:
+		f.openexClient = invalidClient{err: f.clientErr}
+	default:
+		f.openexClient = openex.NewClient(f.clientConfig)
+	}
+
+	return f
+}
+
+func newFxWithClient(client openex.Client, options ...Option) *Fx {
+	return New(append([]Option{WithProvider(client)}, options...)...)
 }
 
-func newFxWithClient(client openex.Client) *Fx {
-	return &Fx{openexClient: client}
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
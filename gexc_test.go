@@ -346,3 +346,137 @@ func TestFx_Convert(t *testing.T) {
 		})
 	}
 }
+
+func TestFx_ConvertAt(t *testing.T) {
+	type fields struct {
+		openexClient openex.Client
+	}
+
+	type args struct {
+		amount float64
+		from   string
+		to     string
+		date   time.Time
+	}
+
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    response.Conversion
+		wantErr bool
+	}{
+		{
+			name: "should convert 5 TRY to EUR with rates of given date",
+			fields: fields{
+				openexClient: testClient{},
+			},
+			args: args{
+				amount: 5,
+				from:   "TRY",
+				to:     "eur",
+				date:   time.Date(2020, 11, 29, 15, 0, 0, 0, time.UTC),
+			},
+			want: response.Conversion{
+				Amount:        5,
+				From:          "TRY",
+				To:            "EUR",
+				Rate:          8.0,
+				CustomerRate:  8.0,
+				Result:        40.0,
+				RequestedDate: time2.NewGexc(time.Date(2020, 11, 29, 0, 0, 0, 0, time.UTC)),
+				Date:          time2.NewGexc(time.Date(2020, 11, 29, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name: "should report previous published date if given date is not published",
+			fields: fields{
+				openexClient: testClient{},
+			},
+			args: args{
+				amount: 5,
+				from:   "TRY",
+				to:     "EUR",
+				date:   time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: response.Conversion{
+				Amount:        5,
+				From:          "TRY",
+				To:            "EUR",
+				Rate:          8.0,
+				CustomerRate:  8.0,
+				Result:        40.0,
+				RequestedDate: time2.NewGexc(time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)),
+				Date:          time2.NewGexc(time.Date(2020, 11, 29, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name: "should raise an error if returned rates are newer than given date",
+			fields: fields{
+				openexClient: testClient{},
+			},
+			args: args{
+				amount: 5,
+				from:   "TRY",
+				to:     "EUR",
+				date:   time.Date(2020, 11, 28, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should raise an error if date is empty",
+			fields: fields{
+				openexClient: testClient{},
+			},
+			args: args{
+				amount: 5,
+				from:   "TRY",
+				to:     "EUR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should raise an error if date is in the future",
+			fields: fields{
+				openexClient: testClient{},
+			},
+			args: args{
+				amount: 5,
+				from:   "TRY",
+				to:     "EUR",
+				date:   time.Date(2020, 12, 30, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should raise an error if unknown currency exist",
+			fields: fields{
+				openexClient: testClient{},
+			},
+			args: args{
+				amount: 5,
+				from:   "UNKNOWN",
+				to:     "EUR",
+				date:   time.Date(2020, 11, 29, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Fx{
+				openexClient: tt.fields.openexClient,
+				clock:        time2.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC)),
+			}
+			got, err := f.ConvertAt(tt.args.amount, tt.args.from, tt.args.to, tt.args.date)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConvertAt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertAt() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
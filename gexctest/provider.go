@@ -0,0 +1,299 @@
+//Package gexctest provides utilities for testing the code that uses gexc.
+//
+//Provider is a configurable fake provider that can be plugged into Fx
+//by NewFx, and NewServer starts a fake exchangeratesapi.io server for
+//end to end tests of the default provider.
+package gexctest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"sync"
+	"time"
+)
+
+//Method is a method of the provider
+type Method string
+
+const (
+	Latest     Method = "Latest"
+	SingleDate Method = "SingleDate"
+	History    Method = "History"
+)
+
+//Call is a request that the provider received
+type Call struct {
+	Method  Method
+	Base    string
+	Symbols []string
+	StartAt time.Time
+	EndAt   time.Time
+}
+
+//ErrNoRates is returned if the provider has no rates for the request
+var ErrNoRates = errors.New("no rates")
+
+//Provider is a fake provider that serves the configured rate tables.
+//Tables are kept in a single base and rebased for the requests of
+//other bases. Rates of a date are served from the table of that date,
+//the previous date that has a table or the static table, in order.
+//It is safe for concurrent use.
+type Provider struct {
+	mu       sync.Mutex
+	base     string
+	static   types.RateItem
+	tables   map[string]types.RateItem
+	errs     map[Method]error
+	dateErrs map[string]error
+	latency  time.Duration
+	calendar gtime.Calendar
+	clock    gtime.Clock
+	calls    []Call
+}
+
+//NewProvider creates a provider whose tables are based on base currency
+func NewProvider(base string) *Provider {
+	return &Provider{
+		base:     base,
+		tables:   make(map[string]types.RateItem),
+		errs:     make(map[Method]error),
+		dateErrs: make(map[string]error),
+		clock:    gtime.SystemClock{},
+	}
+}
+
+//NewFx creates Fx that uses the provider
+func NewFx(provider gexc.Provider, options ...gexc.Option) *gexc.Fx {
+	return gexc.New(append(options, gexc.WithProvider(provider))...)
+}
+
+//SetStatic sets the table of the dates that do not have their own table.
+//Static rates are published on the publication days of the calendar.
+func (p *Provider) SetStatic(rates types.RateItem) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.static = rates
+	return p
+}
+
+//SetRates sets the table of the given date
+func (p *Provider) SetRates(date time.Time, rates types.RateItem) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tables[date.Format(gtime.GexcLayout)] = rates
+	return p
+}
+
+//SetCalendar sets the publication calendar of the static rates,
+//default is gtime.TargetCalendar
+func (p *Provider) SetCalendar(calendar gtime.Calendar) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calendar = calendar
+	return p
+}
+
+//SetClock sets the clock that decides the date of the latest rates.
+//Pass the same clock to Fx by gexc.WithClock to keep them in sync.
+func (p *Provider) SetClock(clock gtime.Clock) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clock = clock
+	return p
+}
+
+//FailWith makes the given method return err, nil err removes the failure
+func (p *Provider) FailWith(method Method, err error) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.errs[method] = err
+	return p
+}
+
+//FailAt makes the requests that include the given date return err
+func (p *Provider) FailAt(date time.Time, err error) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.dateErrs[date.Format(gtime.GexcLayout)] = err
+	return p
+}
+
+//SetLatency delays all responses by d
+func (p *Provider) SetLatency(d time.Duration) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latency = d
+	return p
+}
+
+//Calls returns the requests that the provider received
+func (p *Provider) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Call(nil), p.calls...)
+}
+
+func (p *Provider) Latest(params gexc.LatestParams) (*response.SingleDate, error) {
+	date, err := p.begin(Call{Method: Latest, Base: params.Base, Symbols: params.Symbols})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.singleDate(date, params.Base, params.Symbols)
+}
+
+func (p *Provider) SingleDate(params gexc.SingleDateParams) (*response.SingleDate, error) {
+	_, err := p.begin(Call{
+		Method:  SingleDate,
+		Base:    params.Base,
+		Symbols: params.Symbols,
+		StartAt: params.Date.Time,
+		EndAt:   params.Date.Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.singleDate(params.Date.Time, params.Base, params.Symbols)
+}
+
+func (p *Provider) History(params gexc.HistoryParams) (*response.History, error) {
+	_, err := p.begin(Call{
+		Method:  History,
+		Base:    params.Base,
+		Symbols: params.Symbols,
+		StartAt: params.StartAt.Time,
+		EndAt:   params.EndAt.Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	history := &response.History{
+		Base:    params.Base,
+		StartAt: params.StartAt,
+		EndAt:   params.EndAt,
+		Rates:   make(types.TimeRateItem),
+	}
+
+	for date := startOfDay(params.StartAt.Time); !date.After(params.EndAt.Time); date = date.AddDate(0, 0, 1) {
+		day := date.Format(gtime.GexcLayout)
+		if err := p.dateErrs[day]; err != nil {
+			return nil, err
+		}
+
+		rates, ok := p.tables[day]
+		if !ok && p.static != nil && gtime.IsPublicationDay(p.calendar, date) {
+			rates, ok = p.static, true
+		}
+
+		if !ok {
+			continue
+		}
+
+		table, err := p.table(rates, params.Base, params.Symbols)
+		if err != nil {
+			return nil, err
+		}
+
+		history.Rates[day] = table
+	}
+
+	return history, nil
+}
+
+//begin records the call, waits for the latency and returns the
+//injected error of the method. It returns the current time.
+func (p *Provider) begin(call Call) (time.Time, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, call)
+	latency, err := p.latency, p.errs[call.Method]
+	now := p.clock.Now()
+	p.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	return now, err
+}
+
+func (p *Provider) singleDate(date time.Time, base string, symbols []string) (*response.SingleDate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	day := date.Format(gtime.GexcLayout)
+	if err := p.dateErrs[day]; err != nil {
+		return nil, err
+	}
+
+	published, rates, ok := p.ratesAt(date)
+	if !ok {
+		return nil, fmt.Errorf("%w at %v", ErrNoRates, day)
+	}
+
+	table, err := p.table(rates, base, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.SingleDate{Base: base, Rates: table, Date: gtime.NewGexc(published)}, nil
+}
+
+//ratesAt returns the rates that are published at or before the date
+func (p *Provider) ratesAt(date time.Time) (time.Time, types.RateItem, bool) {
+	day := date.Format(gtime.GexcLayout)
+
+	var latest string
+	for d := range p.tables {
+		if d <= day && d > latest {
+			latest = d
+		}
+	}
+
+	if p.static != nil {
+		//static rates are not served if the calendar never publishes
+		published, err := gtime.LatestPublicationDay(p.calendar, date)
+		if err == nil && (latest == "" || published.Format(gtime.GexcLayout) > latest) {
+			return published, p.static, true
+		}
+	}
+
+	if latest == "" {
+		return time.Time{}, nil, false
+	}
+
+	published, _ := time.Parse(gtime.GexcLayout, latest)
+	return published, p.tables[latest], true
+}
+
+//table rebases the rates and filters them by symbols
+func (p *Provider) table(rates types.RateItem, base string, symbols []string) (types.RateItem, error) {
+	selected, err := (response.SingleDate{Base: p.base, Rates: rates}).Select(base, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	return selected.Rates, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var _ gexc.Provider = (*Provider)(nil)
@@ -0,0 +1,136 @@
+package gexctest
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testProvider() *Provider {
+	return NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": 8, "USD": 1.25}).
+		SetRates(date(2020, 12, 28), types.RateItem{"TRY": 9, "USD": 1.2})
+}
+
+func TestProvider_SingleDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     time.Time
+		base     string
+		want     types.RateItem
+		wantDate string
+	}{
+		{
+			name:     "should serve the table of the date",
+			date:     date(2020, 12, 28),
+			base:     "EUR",
+			want:     types.RateItem{"TRY": 9, "USD": 1.2},
+			wantDate: "2020-12-28",
+		},
+		{
+			name:     "should serve the previous table for days without publication",
+			date:     date(2020, 12, 27),
+			base:     "EUR",
+			want:     types.RateItem{"TRY": 8, "USD": 1.25},
+			wantDate: "2020-12-24",
+		},
+		{
+			name:     "should rebase the table",
+			date:     date(2020, 12, 29),
+			base:     "USD",
+			want:     types.RateItem{"TRY": 6.4, "EUR": 0.8},
+			wantDate: "2020-12-29",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFx(testProvider()).BasedOn(tt.base).Against().At(tt.date)
+			if err != nil {
+				t.Fatalf("At() error = %v", err)
+			}
+
+			if got.Date.String() != tt.wantDate || len(got.Rates) != len(tt.want) {
+				t.Errorf("At() got = %v, want %v %v", got, tt.wantDate, tt.want)
+			}
+
+			for code, rate := range tt.want {
+				if math.Abs(got.Rates[code]-rate) > 1e-9 {
+					t.Errorf("At() rate of %v = %v, want %v", code, got.Rates[code], rate)
+				}
+			}
+		})
+	}
+}
+
+func TestProvider_History(t *testing.T) {
+	got, err := NewFx(testProvider()).
+		BasedOn("EUR").
+		Against("TRY").
+		From(date(2020, 12, 23)).
+		Until(date(2020, 12, 28))
+	if err != nil {
+		t.Fatalf("Until() error = %v", err)
+	}
+
+	want := types.TimeRateItem{
+		"2020-12-23": {"TRY": 8},
+		"2020-12-24": {"TRY": 8},
+		"2020-12-28": {"TRY": 9},
+	}
+
+	if !reflect.DeepEqual(got.Rates, want) {
+		t.Errorf("Until() got = %v, want %v", got.Rates, want)
+	}
+}
+
+func TestProvider_Failures(t *testing.T) {
+	errDown := errors.New("service is down")
+
+	provider := testProvider().
+		FailWith(Latest, errDown).
+		FailAt(date(2020, 12, 22), errDown)
+
+	fx := NewFx(provider)
+
+	if _, err := fx.BasedOn("EUR").Against().Latest(); !errors.Is(err, gexc.ErrClientFailed) {
+		t.Errorf("Latest() error = %v, want %v", err, gexc.ErrClientFailed)
+	}
+
+	if _, err := fx.BasedOn("EUR").Against().At(date(2020, 12, 22)); err == nil {
+		t.Errorf("At() error = nil for failed date")
+	}
+
+	if _, err := fx.BasedOn("EUR").Against().At(date(2020, 12, 23)); err != nil {
+		t.Errorf("At() error = %v", err)
+	}
+
+	if _, err := fx.BasedOn("GBP").Against().At(date(2020, 12, 23)); err == nil {
+		t.Errorf("At() error = nil for unknown base")
+	}
+
+	if calls := provider.Calls(); len(calls) != 4 || calls[0].Method != Latest {
+		t.Errorf("Calls() = %v", calls)
+	}
+}
+
+func TestProvider_SetLatency(t *testing.T) {
+	provider := testProvider().SetLatency(20 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := NewFx(provider).Convert(1, "EUR", "TRY"); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Convert() took %v, want at least the latency", elapsed)
+	}
+}
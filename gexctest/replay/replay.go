@@ -0,0 +1,250 @@
+//Package replay records HTTP responses to fixture files and replays them,
+//so the providers can be tested offline against real payloads.
+//
+//	transport := replay.New("testdata", replay.ModeFromEnv("GEXC_REPLAY", replay.Replay))
+//	fx := gexc.New(gexc.WithHTTPClient(transport.Client()))
+//
+//Fixtures are refreshed by running the tests with GEXC_REPLAY=record.
+//Secrets in the query, like access keys, are scrubbed before the
+//fixtures are written and they are not used to match the requests.
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Mode decides how the transport handles the requests
+type Mode int
+
+const (
+	//Replay serves the requests from the fixtures and
+	//fails if a request is not recorded
+	Replay Mode = iota
+	//Record sends all requests upstream and saves the responses
+	Record
+	//Auto serves the recorded requests and records the others
+	Auto
+)
+
+var modes = map[string]Mode{
+	"replay": Replay,
+	"record": Record,
+	"auto":   Auto,
+}
+
+//ErrNotRecorded is returned in Replay mode if a request has no fixture
+var ErrNotRecorded = errors.New("request is not recorded")
+
+//scrubbed is the value that replaces the secrets
+const scrubbed = "REDACTED"
+
+//DefaultSecrets are the query parameters that are scrubbed by default
+var DefaultSecrets = []string{"access_key", "api_key", "apikey", "app_id"}
+
+//Fixture is a recorded response
+type Fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+//Transport is a http.RoundTripper that records and replays the responses.
+//It is safe for concurrent use.
+type Transport struct {
+	//Dir is the directory of the fixture files
+	Dir  string
+	Mode Mode
+	//Upstream sends the requests that are recorded,
+	//http.DefaultTransport is used if it is nil
+	Upstream http.RoundTripper
+	//Secrets are the query parameters that are scrubbed
+	Secrets []string
+
+	mu sync.Mutex
+}
+
+//New creates a transport that keeps its fixtures in dir
+func New(dir string, mode Mode) *Transport {
+	return &Transport{
+		Dir:     dir,
+		Mode:    mode,
+		Secrets: DefaultSecrets,
+	}
+}
+
+//ModeFromEnv reads the mode from the environment variable,
+//e.g. GEXC_REPLAY=record. It returns fallback if the variable
+//is empty and panics if it is not a known mode.
+func ModeFromEnv(name string, fallback Mode) Mode {
+	value := strings.ToLower(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+
+	mode, ok := modes[value]
+	if !ok {
+		panic(fmt.Sprintf("replay: invalid mode %q in %v", value, name))
+	}
+
+	return mode
+}
+
+//Client returns a http client that uses the transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := t.path(req)
+
+	if t.Mode != Record {
+		fixture, err := t.load(path)
+		if err == nil {
+			return fixture.response(req), nil
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if t.Mode == Replay {
+			return nil, fmt.Errorf("%w: %v %v", ErrNotRecorded, req.Method, t.scrub(req.URL))
+		}
+	}
+
+	return t.record(req, path)
+}
+
+func (t *Transport) record(req *http.Request, path string) (*http.Response, error) {
+	upstream := t.Upstream
+	if upstream == nil {
+		upstream = http.DefaultTransport
+	}
+
+	resp, err := upstream.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := Fixture{
+		Method: req.Method,
+		URL:    t.scrub(req.URL),
+		Status: resp.StatusCode,
+		Header: http.Header{},
+		Body:   string(body),
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		fixture.Header.Set("Content-Type", contentType)
+	}
+
+	if err := t.save(path, fixture); err != nil {
+		return nil, err
+	}
+
+	return fixture.response(req), nil
+}
+
+func (t *Transport) load(path string) (Fixture, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var fixture Fixture
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return fixture, fmt.Errorf("invalid fixture %v: %v", path, err)
+	}
+
+	return fixture, nil
+}
+
+func (t *Transport) save(path string, fixture Fixture) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(fixture); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data.Bytes(), 0644)
+}
+
+//path returns the fixture file of the request. It is named by the
+//hash of the method and the scrubbed url, so the secrets and the
+//order of the query parameters do not change it.
+func (t *Transport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + t.scrub(req.URL)))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+//scrub replaces the secrets of the url and sorts its query
+func (t *Transport) scrub(u *url.URL) string {
+	scrubbedURL := *u
+	query := u.Query()
+
+	for _, secret := range t.Secrets {
+		if _, ok := query[secret]; ok {
+			query.Set(secret, scrubbed)
+		}
+	}
+
+	scrubbedURL.RawQuery = query.Encode()
+	scrubbedURL.User = nil
+	return scrubbedURL.String()
+}
+
+func (f Fixture) response(req *http.Request) *http.Response {
+	header := f.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(f.Body))),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
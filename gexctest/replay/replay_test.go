@@ -0,0 +1,146 @@
+package replay
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func upstream(t *testing.T) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base":"` + r.URL.Query().Get("base") + `"}`))
+	}))
+
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func get(t *testing.T, client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body), nil
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		recorded bool
+		wantHits int32
+		wantErr  error
+	}{
+		{
+			name:     "should replay recorded requests",
+			mode:     Replay,
+			recorded: true,
+			wantHits: 1,
+		},
+		{
+			name:    "should fail on unrecorded requests in replay mode",
+			mode:    Replay,
+			wantErr: ErrNotRecorded,
+		},
+		{
+			name:     "should record requests again in record mode",
+			mode:     Record,
+			recorded: true,
+			wantHits: 2,
+		},
+		{
+			name:     "should replay recorded requests in auto mode",
+			mode:     Auto,
+			recorded: true,
+			wantHits: 1,
+		},
+		{
+			name:     "should record unrecorded requests in auto mode",
+			mode:     Auto,
+			wantHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, hits := upstream(t)
+			dir := t.TempDir()
+			url := server.URL + "/latest?base=EUR&access_key=secret"
+
+			if tt.recorded {
+				if _, err := get(t, New(dir, Record).Client(), url); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			//recorded requests match with another key and query order
+			got, err := get(t, New(dir, tt.mode).Client(), server.URL+"/latest?access_key=other&base=EUR")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got != `{"base":"EUR"}` {
+				t.Errorf("Get() got = %v", got)
+			}
+
+			if *hits != tt.wantHits {
+				t.Errorf("upstream hits = %v, want %v", *hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestTransport_ScrubsSecrets(t *testing.T) {
+	server, _ := upstream(t)
+	dir := t.TempDir()
+
+	_, err := get(t, New(dir, Record).Client(), server.URL+"/latest?base=EUR&access_key=secret&apikey=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("fixtures = %v, error = %v", files, err)
+	}
+
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "secret") || !strings.Contains(string(data), "access_key=REDACTED") {
+		t.Errorf("fixture is not scrubbed: %s", data)
+	}
+}
+
+func TestModeFromEnv(t *testing.T) {
+	defer os.Unsetenv("GEXC_REPLAY")
+
+	_ = os.Unsetenv("GEXC_REPLAY")
+	if got := ModeFromEnv("GEXC_REPLAY", Auto); got != Auto {
+		t.Errorf("ModeFromEnv() got = %v, want fallback", got)
+	}
+
+	_ = os.Setenv("GEXC_REPLAY", "Record")
+	if got := ModeFromEnv("GEXC_REPLAY", Replay); got != Record {
+		t.Errorf("ModeFromEnv() got = %v, want %v", got, Record)
+	}
+}
@@ -0,0 +1,25 @@
+package gexctest
+
+import (
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/server"
+	"net/http"
+	"net/http/httptest"
+)
+
+//NewServer starts a fake exchangeratesapi.io server that serves
+//the rates of the provider. Fx can be pointed to it by gexc.WithEndpoint.
+//
+//	server := gexctest.NewServer(provider)
+//	defer server.Close()
+//	fx := gexc.New(gexc.WithEndpoint(server.URL))
+func NewServer(provider gexc.Provider) *httptest.Server {
+	return httptest.NewServer(Handler(provider))
+}
+
+//Handler serves /latest, /{date} and /history
+//endpoints of exchangeratesapi.io from the provider.
+//It is the handler of package server without cache.
+func Handler(provider gexc.Provider) http.Handler {
+	return server.New(provider)
+}
@@ -0,0 +1,54 @@
+package gexctest
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"testing"
+)
+
+func TestNewServer(t *testing.T) {
+	server := NewServer(testProvider())
+	defer server.Close()
+
+	fx := gexc.New(gexc.WithEndpoint(server.URL))
+
+	t.Run("should serve rates of a date", func(t *testing.T) {
+		got, err := fx.BasedOn("EUR").Against("TRY", "USD").At(date(2020, 12, 28))
+		if err != nil {
+			t.Fatalf("At() error = %v", err)
+		}
+
+		want := types.RateItem{"TRY": 9, "USD": 1.2}
+		if !reflect.DeepEqual(got.Rates, want) || got.Date.String() != "2020-12-28" || got.Base != "EUR" {
+			t.Errorf("At() got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("should serve history", func(t *testing.T) {
+		got, err := fx.BasedOn("EUR").Against("TRY").From(date(2020, 12, 24)).Until(date(2020, 12, 28))
+		if err != nil {
+			t.Fatalf("Until() error = %v", err)
+		}
+
+		want := types.TimeRateItem{"2020-12-24": {"TRY": 8}, "2020-12-28": {"TRY": 9}}
+		if !reflect.DeepEqual(got.Rates, want) || got.StartAt.String() != "2020-12-24" {
+			t.Errorf("Until() got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("should serve latest rates", func(t *testing.T) {
+		got, err := fx.Convert(2, "EUR", "TRY")
+		if err != nil || got != 16 {
+			t.Errorf("Convert() got = %v, error = %v", got, err)
+		}
+	})
+
+	t.Run("should return errors of the provider", func(t *testing.T) {
+		_, err := fx.BasedOn("GBP").Against().Latest()
+		if !errors.Is(err, gexc.ErrClientFailed) {
+			t.Errorf("Latest() error = %v, want %v", err, gexc.ErrClientFailed)
+		}
+	})
+}
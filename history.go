@@ -0,0 +1,155 @@
+package gexc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Window is a part of the history range
+//that is fetched with a single request
+type Window struct {
+	StartAt time.Time
+	EndAt   time.Time
+}
+
+func (w Window) String() string {
+	return gtime.NewGexc(w.StartAt).String() + "/" + gtime.NewGexc(w.EndAt).String()
+}
+
+//WindowError is the failure of a single window
+type WindowError struct {
+	Window Window
+	Err    error
+}
+
+//HistoryError is returned if some windows of the history could not be fetched.
+//It wraps ErrClientFailed and matches the errors of the failed windows,
+//so typed errors of custom providers can be checked by errors.Is and errors.As.
+type HistoryError struct {
+	Failed  []WindowError
+	Windows int
+}
+
+func (e *HistoryError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+	for _, failed := range e.Failed {
+		failures = append(failures, fmt.Sprintf("%v: %v", failed.Window, failed.Err))
+	}
+
+	return fmt.Sprintf("%v: %d of %d history windows failed: %v",
+		ErrClientFailed, len(e.Failed), e.Windows, strings.Join(failures, "; "))
+}
+
+func (e *HistoryError) Unwrap() error {
+	return ErrClientFailed
+}
+
+//Is reports whether any window failed with target
+func (e *HistoryError) Is(target error) bool {
+	for _, failed := range e.Failed {
+		if errors.Is(failed.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//As finds the first window error that matches target
+func (e *HistoryError) As(target interface{}) bool {
+	for _, failed := range e.Failed {
+		if errors.As(failed.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//history fetches the windows of the range concurrently and merges them
+func (f *Fx) history(params openex.HistoryParams) (response.History, error) {
+	windows := splitWindows(params.StartAt.Time, params.EndAt.Time, f.window())
+	responses := make([]*response.History, len(windows))
+	errs := make([]error, len(windows))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, f.workers())
+
+	for i := range windows {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			responses[i], errs[i] = f.openexClient.History(windowParams(params, windows[i]))
+		}(i)
+	}
+
+	wg.Wait()
+
+	history := response.History{
+		Base:    params.Base,
+		StartAt: params.StartAt,
+		EndAt:   params.EndAt,
+		Rates:   make(types.TimeRateItem),
+	}
+
+	historyErr := &HistoryError{Windows: len(windows)}
+	for i, resp := range responses {
+		if errs[i] != nil {
+			historyErr.Failed = append(historyErr.Failed, WindowError{Window: windows[i], Err: errs[i]})
+			continue
+		}
+
+		if len(windows) == 1 {
+			return *resp, nil
+		}
+
+		for date, rates := range resp.Rates {
+			history.Rates[date] = rates
+		}
+	}
+
+	if len(historyErr.Failed) == len(windows) {
+		return response.History{}, historyErr
+	}
+
+	if len(historyErr.Failed) > 0 {
+		return history, historyErr
+	}
+
+	return history, nil
+}
+
+func windowParams(params openex.HistoryParams, window Window) openex.HistoryParams {
+	params.StartAt = gtime.NewGexc(window.StartAt)
+	params.EndAt = gtime.NewGexc(window.EndAt)
+	return params
+}
+
+//splitWindows splits the range into windows of the given number of days.
+//Both ends of the range and the windows are inclusive.
+func splitWindows(from, until time.Time, days int) []Window {
+	var windows []Window
+	for start := from; !start.After(until); start = start.AddDate(0, 0, days) {
+		end := start.AddDate(0, 0, days-1)
+		if end.After(until) {
+			end = until
+		}
+
+		windows = append(windows, Window{StartAt: start, EndAt: end})
+	}
+
+	return windows
+}
@@ -0,0 +1,171 @@
+package gexc
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	time2 "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+//windowTestClient returns a rate for every day of the requested range
+//and fails the windows that start at the dates in failAt
+type windowTestClient struct {
+	mu      sync.Mutex
+	windows []string
+	failAt  map[string]bool
+	//delay is the duration of the requests,
+	//active and maxActive count the concurrent ones
+	delay     time.Duration
+	active    int
+	maxActive int
+}
+
+func (w *windowTestClient) Latest(params openex.LatestParams) (*response.SingleDate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (w *windowTestClient) SingleDate(params openex.SingleDateParams) (*response.SingleDate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (w *windowTestClient) History(params openex.HistoryParams) (*response.History, error) {
+	w.mu.Lock()
+	w.windows = append(w.windows, params.StartAt.String()+"/"+params.EndAt.String())
+	if w.active++; w.active > w.maxActive {
+		w.maxActive = w.active
+	}
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		w.active--
+		w.mu.Unlock()
+	}()
+
+	time.Sleep(w.delay)
+
+	if w.failAt[params.StartAt.String()] {
+		return nil, errors.New("range is too long")
+	}
+
+	rates := make(types.TimeRateItem)
+	for d := params.StartAt.Time; !d.After(params.EndAt.Time); d = d.AddDate(0, 0, 1) {
+		rates[d.Format(time2.GexcLayout)] = types.RateItem{"TRY": float64(d.Day())}
+	}
+
+	return &response.History{Base: params.Base, StartAt: params.StartAt, EndAt: params.EndAt, Rates: rates}, nil
+}
+
+func Test_splitWindows(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  time.Time
+		until time.Time
+		days  int
+		want  []Window
+	}{
+		{
+			name:  "should return a single window for short ranges",
+			from:  time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			until: time.Date(2020, 12, 3, 0, 0, 0, 0, time.UTC),
+			days:  5,
+			want: []Window{
+				{StartAt: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), EndAt: time.Date(2020, 12, 3, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "should split long ranges into windows",
+			from:  time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			until: time.Date(2020, 12, 7, 0, 0, 0, 0, time.UTC),
+			days:  3,
+			want: []Window{
+				{StartAt: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), EndAt: time.Date(2020, 12, 3, 0, 0, 0, 0, time.UTC)},
+				{StartAt: time.Date(2020, 12, 4, 0, 0, 0, 0, time.UTC), EndAt: time.Date(2020, 12, 6, 0, 0, 0, 0, time.UTC)},
+				{StartAt: time.Date(2020, 12, 7, 0, 0, 0, 0, time.UTC), EndAt: time.Date(2020, 12, 7, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitWindows(tt.from, tt.until, tt.days); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitWindows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFx_HistoryWindows(t *testing.T) {
+	from := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2020, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		failAt      map[string]bool
+		wantWindows []string
+		wantDates   int
+		wantFailed  []string
+	}{
+		{
+			name:        "should merge all windows into a single history",
+			wantWindows: []string{"2020-12-01/2020-12-04", "2020-12-05/2020-12-08", "2020-12-09/2020-12-10"},
+			wantDates:   10,
+		},
+		{
+			name:        "should return the successful windows and report the failed ones",
+			failAt:      map[string]bool{"2020-12-05": true},
+			wantWindows: []string{"2020-12-01/2020-12-04", "2020-12-05/2020-12-08", "2020-12-09/2020-12-10"},
+			wantDates:   6,
+			wantFailed:  []string{"2020-12-05/2020-12-08"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &windowTestClient{failAt: tt.failAt}
+			f := newFxWithClient(client, WithHistoryWindow(4), WithConcurrency(2))
+
+			got, err := f.BasedOn("EUR").Against("TRY").From(from).Until(until)
+
+			sort.Strings(client.windows)
+			if !reflect.DeepEqual(client.windows, tt.wantWindows) {
+				t.Errorf("Until() windows = %v, want %v", client.windows, tt.wantWindows)
+			}
+
+			if len(got.Rates) != tt.wantDates {
+				t.Errorf("Until() dates = %v, want %v", len(got.Rates), tt.wantDates)
+			}
+
+			if !got.StartAt.Equal(from) || !got.EndAt.Equal(until) || got.Base != "EUR" {
+				t.Errorf("Until() got = %v - %v %v", got.StartAt, got.EndAt, got.Base)
+			}
+
+			if tt.wantFailed == nil {
+				if err != nil {
+					t.Errorf("Until() error = %v", err)
+				}
+				return
+			}
+
+			var historyErr *HistoryError
+			if !errors.As(err, &historyErr) || !errors.Is(err, ErrClientFailed) {
+				t.Fatalf("Until() error = %v, want *HistoryError", err)
+			}
+
+			var failed []string
+			for _, w := range historyErr.Failed {
+				failed = append(failed, w.Window.String())
+			}
+
+			if !reflect.DeepEqual(failed, tt.wantFailed) || historyErr.Windows != len(tt.wantWindows) {
+				t.Errorf("Until() failed = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
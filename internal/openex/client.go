@@ -33,6 +33,16 @@ type response struct {
 }
 
 func (c client) toUrl(path string, qp string) url.URL {
+	if c.config.AccessKey != "" {
+		values, _ := url.ParseQuery(qp)
+		values.Set("access_key", c.config.AccessKey)
+		qp = values.Encode()
+	}
+
+	if c.config.BasePath != "" {
+		path = strings.TrimSuffix(c.config.BasePath, "/") + path
+	}
+
 	return url.URL{
 		Scheme:   c.config.Protocol,
 		Host:     c.config.BaseUrl,
@@ -153,8 +163,17 @@ func (c client) History(params HistoryParams) (*rsp.History, error) {
 }
 
 func NewDefaultClient() Client {
+	return NewClient(defaultConfig)
+}
+
+func NewClient(config Config) Client {
+	getter := defaultHttpGetter
+	if config.HTTPClient != nil {
+		getter = config.HTTPClient.Get
+	}
+
 	return &client{
-		config:     defaultConfig,
-		httpGetter: defaultHttpGetter,
+		config:     config,
+		httpGetter: getter,
 	}
 }
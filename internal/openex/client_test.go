@@ -48,6 +48,37 @@ func Test_client_toUrl(t *testing.T) {
 			},
 			want1: fmt.Sprintf("%s://%s/%s?%s", defaultConfig.Protocol, defaultConfig.BaseUrl, "path", "qp1=true&qp2=false"),
 		},
+		{
+			name: "should add access key to query parameters",
+			fields: fields{config: Config{
+				BaseUrl:   defaultConfig.BaseUrl,
+				Protocol:  defaultConfig.Protocol,
+				AccessKey: "secret",
+			}},
+			args: args{path: "path", qp: "base=EUR"},
+			want: url.URL{
+				Scheme:   defaultConfig.Protocol,
+				Host:     defaultConfig.BaseUrl,
+				Path:     "path",
+				RawQuery: "access_key=secret&base=EUR",
+			},
+			want1: fmt.Sprintf("%s://%s/%s?%s", defaultConfig.Protocol, defaultConfig.BaseUrl, "path", "access_key=secret&base=EUR"),
+		},
+		{
+			name: "should prefix the path with base path",
+			fields: fields{config: Config{
+				BaseUrl:  "mirror.local",
+				Protocol: "http",
+				BasePath: "/rates/",
+			}},
+			args: args{path: "/latest"},
+			want: url.URL{
+				Scheme: "http",
+				Host:   "mirror.local",
+				Path:   "/rates/latest",
+			},
+			want1: "http://mirror.local/rates/latest",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
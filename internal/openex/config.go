@@ -1,12 +1,25 @@
 package openex
 
+import "net/http"
+
 type Config struct {
 	BaseUrl  string
 	Protocol string
 	Port     string
+	//BasePath is the path prefix of the endpoints, e.g. of a mirror
+	BasePath string
+	//AccessKey is sent as access_key query parameter if it is not empty
+	AccessKey string
+	//HTTPClient sends the requests, http.DefaultClient is used if it is nil
+	HTTPClient *http.Client
 }
 
 var defaultConfig = Config{
 	BaseUrl:  "api.exchangeratesapi.io",
 	Protocol: "https",
 }
+
+//DefaultConfig returns the configuration of the exchangeratesapi.io
+func DefaultConfig() Config {
+	return defaultConfig
+}
@@ -0,0 +1,112 @@
+package openex
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc/gexctest/replay"
+	rsp "github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+//fixtureClient replays the recorded responses of testdata.
+//Run the tests with GEXC_REPLAY=record to refresh them.
+func fixtureClient() Client {
+	transport := replay.New("testdata", replay.ModeFromEnv("GEXC_REPLAY", replay.Replay))
+
+	config := DefaultConfig()
+	config.HTTPClient = transport.Client()
+	return NewClient(config)
+}
+
+func gexcDate(year int, month time.Month, day int) gtime.Gexc {
+	return gtime.NewGexc(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+func Test_client_Latest(t *testing.T) {
+	got, err := fixtureClient().Latest(LatestParams{Base: "EUR", Symbols: []string{"TRY", "USD"}})
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+
+	want := types.RateItem{"TRY": 9.0196, "USD": 1.2271}
+	if got.Base != "EUR" || got.Date.String() != "2021-01-08" || !reflect.DeepEqual(got.Rates, want) {
+		t.Errorf("Latest() got = %v, want %v", got, want)
+	}
+}
+
+func Test_client_SingleDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  SingleDateParams
+		want    *rsp.SingleDate
+		wantErr bool
+	}{
+		{
+			name:   "should parse the rates of the date",
+			params: SingleDateParams{Date: gexcDate(2020, 12, 24), Base: "EUR", Symbols: []string{"TRY", "USD"}},
+			want: &rsp.SingleDate{
+				Base:  "EUR",
+				Rates: types.RateItem{"TRY": 9.1216, "USD": 1.2193},
+				Date:  gexcDate(2020, 12, 24),
+			},
+		},
+		{
+			name:    "should return the error message of the api",
+			params:  SingleDateParams{Date: gexcDate(1998, 1, 2), Base: "EUR"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fixtureClient().SingleDate(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SingleDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, replay.ErrNotRecorded) {
+				t.Fatalf("SingleDate() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SingleDate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_History(t *testing.T) {
+	got, err := fixtureClient().History(HistoryParams{
+		StartAt: gexcDate(2020, 12, 21),
+		EndAt:   gexcDate(2020, 12, 24),
+		Base:    "EUR",
+		Symbols: []string{"TRY"},
+	})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	want := types.TimeRateItem{
+		"2020-12-22": {"TRY": 9.4453},
+		"2020-12-23": {"TRY": 9.3118},
+		"2020-12-24": {"TRY": 9.1216},
+	}
+
+	if got.Base != "EUR" || got.StartAt.String() != "2020-12-21" || !reflect.DeepEqual(got.Rates, want) {
+		t.Errorf("History() got = %v, want %v", got, want)
+	}
+}
+
+func Test_client_Unrecorded(t *testing.T) {
+	if replay.ModeFromEnv("GEXC_REPLAY", replay.Replay) != replay.Replay {
+		t.Skip("requests are recorded")
+	}
+
+	_, err := fixtureClient().Latest(LatestParams{Base: "GBP"})
+	if !errors.Is(err, replay.ErrNotRecorded) {
+		t.Errorf("Latest() error = %v, want %v", err, replay.ErrNotRecorded)
+	}
+}
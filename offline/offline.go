@@ -0,0 +1,469 @@
+//Package offline serves the rates from a store when the upstream
+//provider fails, e.g. on devices that are intermittently offline.
+//Responses of the upstream are saved to the store, so a Memory
+//store works as a cache and an SQL store survives restarts.
+//
+//	provider := offline.New(gexc.New().Provider(), archive,
+//		offline.WithMaxStaleness(72*time.Hour),
+//		offline.WithObserver(func(status offline.Status) {
+//			if status.Stale {
+//				log.Printf("rates of %v are %v old", status.Date, status.Age)
+//			}
+//		}))
+//
+//	fx := gexc.New(gexc.WithProvider(provider))
+package offline
+
+import (
+	"context"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/store"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"strings"
+	"time"
+)
+
+//defaultBase is the base of the upstream if the request has none
+const defaultBase = "EUR"
+
+//Method is a method of the provider
+type Method int
+
+const (
+	Latest Method = iota + 1
+	SingleDate
+	History
+)
+
+func (m Method) String() string {
+	switch m {
+	case Latest:
+		return "latest"
+	case SingleDate:
+		return "single date"
+	case History:
+		return "history"
+	default:
+		return fmt.Sprintf("Method(%d)", int(m))
+	}
+}
+
+//Request identifies the request of a response, so the statuses of
+//concurrent requests, e.g. of batch conversions and history windows,
+//can be told apart
+type Request struct {
+	Method  Method
+	Base    string
+	Symbols []string
+	//StartAt and EndAt are the requested dates, both are the
+	//date for SingleDate and they are zero for Latest
+	StartAt time.Time
+	EndAt   time.Time
+}
+
+//Status tells where a response comes from
+type Status struct {
+	Request Request
+	//Offline tells whether the response is served from the store
+	Offline bool
+	//Stale tells whether newer rates are published than the served ones
+	Stale bool
+	//Date is the publication day of the served rates,
+	//the last one for the history
+	Date time.Time
+	//Age is how long newer rates have been published, zero if not stale.
+	//It is measured at the requested date for the past dates.
+	Age time.Duration
+	//Err is the failure of the upstream if the response is served from the store
+	Err error
+	//SaveErr is the failure of saving the response of the upstream to the store
+	SaveErr error
+}
+
+//StaleError is returned if the upstream fails and
+//the stored rates are older than the maximum staleness
+type StaleError struct {
+	Request Request
+	Date    time.Time
+	Age     time.Duration
+	Max     time.Duration
+	//Err is the failure of the upstream
+	Err error
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("stored rates of %v are stale for %v, max staleness is %v: %v",
+		gtime.NewGexc(e.Date), e.Age, e.Max, e.Err)
+}
+
+func (e *StaleError) Unwrap() error {
+	return e.Err
+}
+
+//Provider serves the rates of the upstream provider and
+//falls back to the stored rates if the upstream fails.
+//It is safe for concurrent use if the store is.
+type Provider struct {
+	upstream     gexc.Provider
+	store        store.Store
+	source       string
+	clock        gtime.Clock
+	calendar     gtime.Calendar
+	archiveBase  string
+	maxStaleness time.Duration
+	writeThrough bool
+	observer     func(Status)
+}
+
+//Option customizes Provider while it is created by New
+type Option func(p *Provider)
+
+//WithSource sets the source of the stored rates, default is store.DefaultSource
+func WithSource(source string) Option {
+	return func(p *Provider) {
+		p.source = source
+	}
+}
+
+//WithClock sets the clock that decides the staleness,
+//default is gtime.SystemClock
+func WithClock(clock gtime.Clock) Option {
+	return func(p *Provider) {
+		if clock != nil {
+			p.clock = clock
+		}
+	}
+}
+
+//WithCalendar sets the publication calendar of the rates,
+//default is gtime.TargetCalendar
+func WithCalendar(calendar gtime.Calendar) Option {
+	return func(p *Provider) {
+		p.calendar = calendar
+	}
+}
+
+//WithArchiveBase sets the base of the archived rates, e.g. the base of
+//store.Sync, default is EUR. If the store has no rates of the requested
+//base, the archived rates are rebased to it.
+func WithArchiveBase(base string) Option {
+	return func(p *Provider) {
+		p.archiveBase = baseOrDefault(base)
+	}
+}
+
+//WithMaxStaleness makes the provider return *StaleError instead
+//of the stored rates that are stale for longer than max.
+//Zero max, the default, accepts all stored rates.
+func WithMaxStaleness(max time.Duration) Option {
+	return func(p *Provider) {
+		if max >= 0 {
+			p.maxStaleness = max
+		}
+	}
+}
+
+//WithWriteThrough sets whether the responses of the upstream
+//are saved to the store, default is true
+func WithWriteThrough(enabled bool) Option {
+	return func(p *Provider) {
+		p.writeThrough = enabled
+	}
+}
+
+//WithObserver sets the function that receives the status of every
+//successful response with its request. It is called before the
+//response is returned, in the goroutine of the request.
+func WithObserver(observer func(Status)) Option {
+	return func(p *Provider) {
+		p.observer = observer
+	}
+}
+
+//New creates a provider that falls back to the store
+func New(upstream gexc.Provider, archive store.Store, options ...Option) *Provider {
+	p := &Provider{
+		upstream:     upstream,
+		store:        archive,
+		source:       store.DefaultSource,
+		clock:        gtime.SystemClock{},
+		archiveBase:  defaultBase,
+		writeThrough: true,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Latest(params gexc.LatestParams) (*response.SingleDate, error) {
+	req := Request{Method: Latest, Base: params.Base, Symbols: params.Symbols}
+	resp, err := p.upstream.Latest(params)
+	if err != nil {
+		return p.storedSingleDate(req, err)
+	}
+
+	p.online(req, resp.Base, resp.Date.Time, types.TimeRateItem{resp.Date.String(): resp.Rates})
+	return resp, nil
+}
+
+func (p *Provider) SingleDate(params gexc.SingleDateParams) (*response.SingleDate, error) {
+	day := truncateDay(params.Date.Time)
+	req := Request{Method: SingleDate, Base: params.Base, Symbols: params.Symbols, StartAt: day, EndAt: day}
+	resp, err := p.upstream.SingleDate(params)
+	if err != nil {
+		return p.storedSingleDate(req, err)
+	}
+
+	p.online(req, resp.Base, resp.Date.Time, types.TimeRateItem{resp.Date.String(): resp.Rates})
+	return resp, nil
+}
+
+func (p *Provider) History(params gexc.HistoryParams) (*response.History, error) {
+	req := Request{
+		Method:  History,
+		Base:    params.Base,
+		Symbols: params.Symbols,
+		StartAt: truncateDay(params.StartAt.Time),
+		EndAt:   truncateDay(params.EndAt.Time),
+	}
+
+	resp, err := p.upstream.History(params)
+	if err != nil {
+		return p.storedHistory(req, err)
+	}
+
+	var last time.Time
+	for day := range resp.Rates {
+		if date, err := time.Parse(gtime.GexcLayout, day); err == nil && date.After(last) {
+			last = date
+		}
+	}
+
+	p.online(req, resp.Base, last, resp.Rates)
+	return resp, nil
+}
+
+//online saves the rates of the upstream and reports them.
+//Failures of the store are reported by the status only.
+func (p *Provider) online(req Request, base string, date time.Time, rates types.TimeRateItem) {
+	status := Status{Date: truncateDay(date)}
+	if p.writeThrough {
+		status.SaveErr = p.save(base, rates)
+	}
+
+	p.observe(req, status)
+}
+
+func (p *Provider) save(base string, tables types.TimeRateItem) error {
+	fetchedAt := p.clock.Now()
+
+	var rates []store.Rate
+	for day, table := range tables {
+		date, err := time.Parse(gtime.GexcLayout, day)
+		if err != nil {
+			return err
+		}
+
+		for code, rate := range table {
+			rates = append(rates, store.Rate{
+				Date:      date,
+				Base:      baseOrDefault(base),
+				Currency:  strings.ToUpper(code),
+				Rate:      rate,
+				Source:    p.source,
+				FetchedAt: fetchedAt,
+			})
+		}
+	}
+
+	if len(rates) == 0 {
+		return nil
+	}
+
+	return p.store.Save(context.Background(), rates)
+}
+
+//storedSingleDate serves the last stored rates until the
+//requested date, or the latest ones for Latest
+func (p *Provider) storedSingleDate(req Request, upstreamErr error) (*response.SingleDate, error) {
+	tables, err := p.stored(store.Query{
+		Source:     p.source,
+		Base:       baseOrDefault(req.Base),
+		Until:      req.EndAt,
+		Currencies: req.Symbols,
+		LatestOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w (store failed: %v)", upstreamErr, err)
+	}
+
+	if len(tables) == 0 {
+		return nil, upstreamErr
+	}
+
+	resp := tables[0]
+	status, err := p.offline(req, resp.Date.Time, upstreamErr)
+	if err != nil {
+		return nil, err
+	}
+
+	p.observe(req, status)
+	return &resp, nil
+}
+
+func (p *Provider) storedHistory(req Request, upstreamErr error) (*response.History, error) {
+	base := baseOrDefault(req.Base)
+	tables, err := p.stored(store.Query{
+		Source:     p.source,
+		Base:       base,
+		From:       req.StartAt,
+		Until:      req.EndAt,
+		Currencies: req.Symbols,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w (store failed: %v)", upstreamErr, err)
+	}
+
+	if len(tables) == 0 {
+		return nil, upstreamErr
+	}
+
+	//tables are ordered by date
+	status, err := p.offline(req, tables[len(tables)-1].Date.Time, upstreamErr)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.History{
+		Base:    base,
+		StartAt: gtime.NewGexc(req.StartAt),
+		EndAt:   gtime.NewGexc(req.EndAt),
+		Rates:   make(types.TimeRateItem, len(tables)),
+	}
+
+	for _, table := range tables {
+		resp.Rates[table.Date.String()] = table.Rates
+	}
+
+	p.observe(req, status)
+	return resp, nil
+}
+
+//stored returns the stored tables of the query ordered by date. If the
+//store has no rates of the base, the tables of the archived base are
+//rebased to it and filtered by the currencies of the query.
+func (p *Provider) stored(query store.Query) ([]response.SingleDate, error) {
+	rates, err := p.store.Rates(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rates) > 0 || query.Base == p.archiveBase {
+		return tables(query.Base, rates), nil
+	}
+
+	base := query.Base
+	symbols := make([]string, len(query.Currencies))
+	for i, code := range query.Currencies {
+		symbols[i] = strings.ToUpper(code)
+	}
+
+	//the rate of the base is needed to rebase the archived tables
+	query.Base, query.Currencies = p.archiveBase, nil
+	rates, err = p.store.Rates(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := tables(p.archiveBase, rates)
+	for i, table := range archived {
+		if archived[i], err = table.Select(base, symbols); err != nil {
+			return nil, fmt.Errorf("rates of %v: %w", table.Date, err)
+		}
+	}
+
+	return archived, nil
+}
+
+//tables groups the rates that are ordered by date
+func tables(base string, rates []store.Rate) []response.SingleDate {
+	var grouped []response.SingleDate
+	for _, rate := range rates {
+		if n := len(grouped); n == 0 || !grouped[n-1].Date.Time.Equal(rate.Date) {
+			grouped = append(grouped, response.SingleDate{
+				Base:  base,
+				Rates: make(types.RateItem),
+				Date:  gtime.NewGexc(rate.Date),
+			})
+		}
+
+		grouped[len(grouped)-1].Rates[rate.Currency] = rate.Rate
+	}
+
+	return grouped
+}
+
+//offline returns the status of the stored rates of the date that are
+//served for the request. Staleness is measured at the end of the
+//requested date, or now for Latest. It returns *StaleError if the
+//rates are stale for longer than the maximum staleness, and the
+//upstream error if the calendar has no publication day within a year.
+func (p *Provider) offline(req Request, date time.Time, upstreamErr error) (Status, error) {
+	at := p.clock.Now()
+	if !req.EndAt.IsZero() {
+		//the rates of the requested date are published before its end
+		if end := req.EndAt.AddDate(0, 0, 1); !at.Before(end) {
+			at = end
+		}
+	}
+
+	published, err := gtime.LatestPublishedDay(p.calendar, at)
+	if err != nil {
+		return Status{}, fmt.Errorf("%w (calendar failed: %v)", upstreamErr, err)
+	}
+
+	status := Status{Offline: true, Date: date, Err: upstreamErr}
+	if date.Before(published) {
+		//the rates are stale since the next publication after the date
+		next, err := gtime.NextPublicationTime(p.calendar, date.AddDate(0, 0, 1))
+		if err != nil {
+			return Status{}, fmt.Errorf("%w (calendar failed: %v)", upstreamErr, err)
+		}
+
+		status.Stale = true
+		status.Age = at.Sub(next)
+	}
+
+	if p.maxStaleness > 0 && status.Age > p.maxStaleness {
+		return Status{}, &StaleError{Request: req, Date: date, Age: status.Age, Max: p.maxStaleness, Err: upstreamErr}
+	}
+
+	return status, nil
+}
+
+func (p *Provider) observe(req Request, status Status) {
+	if p.observer != nil {
+		status.Request = req
+		p.observer(status)
+	}
+}
+
+func baseOrDefault(base string) string {
+	if base == "" {
+		return defaultBase
+	}
+
+	return strings.ToUpper(base)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var _ gexc.Provider = (*Provider)(nil)
@@ -0,0 +1,384 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/gexctest"
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/store"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errNetwork = errors.New("network is unreachable")
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+//testStore returns a store that has the EUR based rates of the dates
+func testStore(t *testing.T, dates ...time.Time) *store.Memory {
+	archive := store.NewMemory()
+	for i, d := range dates {
+		err := archive.Save(context.Background(), []store.Rate{
+			{Date: d, Base: "EUR", Currency: "TRY", Rate: 9 + float64(i), Source: store.DefaultSource},
+			{Date: d, Base: "EUR", Currency: "USD", Rate: 1.2, Source: store.DefaultSource},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return archive
+}
+
+func TestProvider_Latest(t *testing.T) {
+	//tuesday after the publication, 2020-12-25 and 2020-12-26 are holidays
+	now := time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC)
+	req := Request{Method: Latest, Symbols: []string{"try"}}
+
+	tests := []struct {
+		name       string
+		stored     []time.Time
+		maxStale   time.Duration
+		want       *response.SingleDate
+		wantStatus Status
+		wantErr    error
+	}{
+		{
+			name:       "should serve the stored rates if they are up to date",
+			stored:     []time.Time{date(2020, 12, 24), date(2020, 12, 29)},
+			want:       &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 10}, Date: gtime.NewGexc(date(2020, 12, 29))},
+			wantStatus: Status{Request: req, Offline: true, Date: date(2020, 12, 29), Err: errNetwork},
+		},
+		{
+			name:   "should mark the stored rates as stale with their age",
+			stored: []time.Time{date(2020, 12, 24)},
+			want:   &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9}, Date: gtime.NewGexc(date(2020, 12, 24))},
+			//the rates of 2020-12-28 are published at 15:00 UTC
+			wantStatus: Status{Request: req, Offline: true, Stale: true, Date: date(2020, 12, 24), Age: 26 * time.Hour, Err: errNetwork},
+		},
+		{
+			name:       "should accept the stale rates within the max staleness",
+			stored:     []time.Time{date(2020, 12, 24)},
+			maxStale:   26 * time.Hour,
+			want:       &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9}, Date: gtime.NewGexc(date(2020, 12, 24))},
+			wantStatus: Status{Request: req, Offline: true, Stale: true, Date: date(2020, 12, 24), Age: 26 * time.Hour, Err: errNetwork},
+		},
+		{
+			name:     "should raise a stale error beyond the max staleness",
+			stored:   []time.Time{date(2020, 12, 24)},
+			maxStale: 24 * time.Hour,
+			wantErr:  &StaleError{Request: req, Date: date(2020, 12, 24), Age: 26 * time.Hour, Max: 24 * time.Hour, Err: errNetwork},
+		},
+		{
+			name:    "should return the upstream error if nothing is stored",
+			wantErr: errNetwork,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := gexctest.NewProvider("EUR").FailWith(gexctest.Latest, errNetwork)
+
+			var statuses []Status
+			p := New(upstream, testStore(t, tt.stored...),
+				WithClock(gtime.NewManualClock(now)),
+				WithMaxStaleness(tt.maxStale),
+				WithObserver(func(status Status) {
+					statuses = append(statuses, status)
+				}))
+
+			got, err := p.Latest(gexc.LatestParams{Symbols: []string{"try"}})
+
+			var staleErr *StaleError
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("Latest() error = %v", err)
+				}
+			case *StaleError:
+				if !errors.As(err, &staleErr) || !reflect.DeepEqual(staleErr, want) || !errors.Is(err, errNetwork) {
+					t.Errorf("Latest() error = %v, want %v", err, want)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("Latest() error = %v, want %v", err, want)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Latest() got = %v, want %v", got, tt.want)
+			}
+
+			if tt.want != nil && !reflect.DeepEqual(statuses, []Status{tt.wantStatus}) {
+				t.Errorf("Latest() statuses = %+v, want %+v", statuses, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestProvider_WriteThrough(t *testing.T) {
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC))
+	upstream := gexctest.NewProvider("EUR").SetStatic(types.RateItem{"TRY": 9.5}).SetClock(clock)
+	archive := store.NewMemory()
+
+	var statuses []Status
+	p := New(upstream, archive, WithClock(clock), WithObserver(func(status Status) {
+		statuses = append(statuses, status)
+	}))
+
+	if _, err := p.Latest(gexc.LatestParams{Base: "EUR"}); err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(statuses, []Status{{Request: Request{Method: Latest, Base: "EUR"}, Date: date(2020, 12, 29)}}) {
+		t.Errorf("Latest() statuses = %+v", statuses)
+	}
+
+	//the saved rates are served while the network is down
+	upstream.FailWith(gexctest.Latest, errNetwork)
+	clock.Advance(time.Hour)
+
+	got, err := p.Latest(gexc.LatestParams{Base: "EUR"})
+	want := &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9.5}, Date: gtime.NewGexc(date(2020, 12, 29))}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Latest() got = %v, error = %v, want %v", got, err, want)
+	}
+
+	stored, _ := archive.Rates(context.Background(), store.Query{Base: "EUR"})
+	if len(stored) != 1 || !stored[0].FetchedAt.Equal(time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("Rates() got = %v", stored)
+	}
+
+	t.Run("should not save if write through is disabled", func(t *testing.T) {
+		archive := store.NewMemory()
+		upstream.FailWith(gexctest.Latest, nil)
+
+		_, _ = New(upstream, archive, WithWriteThrough(false)).Latest(gexc.LatestParams{Base: "EUR"})
+		if stored, _ := archive.Rates(context.Background(), store.Query{Base: "EUR"}); len(stored) != 0 {
+			t.Errorf("Rates() got = %v", stored)
+		}
+	})
+}
+
+func TestProvider_SingleDate(t *testing.T) {
+	now := time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC)
+	upstream := gexctest.NewProvider("EUR").FailWith(gexctest.SingleDate, errNetwork)
+	archive := testStore(t, date(2020, 12, 23), date(2020, 12, 24))
+
+	var statuses []Status
+	p := New(upstream, archive, WithClock(gtime.NewManualClock(now)), WithObserver(func(status Status) {
+		statuses = append(statuses, status)
+	}))
+
+	//a sunday, the rates of the previous publication day are up to date
+	got, err := p.SingleDate(gexc.SingleDateParams{Date: gtime.NewGexc(date(2020, 12, 27)), Base: "EUR"})
+	want := &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 10, "USD": 1.2}, Date: gtime.NewGexc(date(2020, 12, 24))}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SingleDate() got = %v, error = %v, want %v", got, err, want)
+	}
+
+	got, err = p.SingleDate(gexc.SingleDateParams{Date: gtime.NewGexc(date(2020, 12, 23)), Base: "EUR"})
+	want = &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9, "USD": 1.2}, Date: gtime.NewGexc(date(2020, 12, 23))}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SingleDate() got = %v, error = %v, want %v", got, err, want)
+	}
+
+	//the rates of 2020-12-28 are published at 15:00 UTC, 9 hours before its end
+	got, err = p.SingleDate(gexc.SingleDateParams{Date: gtime.NewGexc(date(2020, 12, 28)), Base: "EUR"})
+	want = &response.SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 10, "USD": 1.2}, Date: gtime.NewGexc(date(2020, 12, 24))}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SingleDate() got = %v, error = %v, want %v", got, err, want)
+	}
+
+	_, err = New(upstream, archive, WithClock(gtime.NewManualClock(now)), WithMaxStaleness(8*time.Hour)).
+		SingleDate(gexc.SingleDateParams{Date: gtime.NewGexc(date(2020, 12, 28)), Base: "EUR"})
+
+	var staleErr *StaleError
+	if !errors.As(err, &staleErr) || staleErr.Age != 9*time.Hour ||
+		err.Error() != "stored rates of 2020-12-24 are stale for 9h0m0s, max staleness is 8h0m0s: network is unreachable" {
+		t.Errorf("SingleDate() error = %v, want *StaleError", err)
+	}
+
+	wantStatuses := []Status{
+		{
+			Request: Request{Method: SingleDate, Base: "EUR", StartAt: date(2020, 12, 27), EndAt: date(2020, 12, 27)},
+			Offline: true,
+			Date:    date(2020, 12, 24),
+			Err:     errNetwork,
+		},
+		{
+			Request: Request{Method: SingleDate, Base: "EUR", StartAt: date(2020, 12, 23), EndAt: date(2020, 12, 23)},
+			Offline: true,
+			Date:    date(2020, 12, 23),
+			Err:     errNetwork,
+		},
+		{
+			Request: Request{Method: SingleDate, Base: "EUR", StartAt: date(2020, 12, 28), EndAt: date(2020, 12, 28)},
+			Offline: true,
+			Stale:   true,
+			Date:    date(2020, 12, 24),
+			Age:     9 * time.Hour,
+			Err:     errNetwork,
+		},
+	}
+
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Errorf("SingleDate() statuses = %+v, want %+v", statuses, wantStatuses)
+	}
+}
+
+func TestProvider_History(t *testing.T) {
+	now := time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC)
+	upstream := gexctest.NewProvider("EUR").FailWith(gexctest.History, errNetwork)
+	archive := testStore(t, date(2020, 12, 21), date(2020, 12, 22), date(2020, 12, 24))
+
+	var statuses []Status
+	p := New(upstream, archive, WithClock(gtime.NewManualClock(now)), WithObserver(func(status Status) {
+		statuses = append(statuses, status)
+	}))
+
+	got, err := p.History(gexc.HistoryParams{
+		StartAt: gtime.NewGexc(date(2020, 12, 22)),
+		EndAt:   gtime.NewGexc(date(2020, 12, 29)),
+		Base:    "EUR",
+		Symbols: []string{"TRY"},
+	})
+
+	want := &response.History{
+		Base:    "EUR",
+		StartAt: gtime.NewGexc(date(2020, 12, 22)),
+		EndAt:   gtime.NewGexc(date(2020, 12, 29)),
+		Rates: types.TimeRateItem{
+			"2020-12-22": {"TRY": 10},
+			"2020-12-24": {"TRY": 11},
+		},
+	}
+
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("History() got = %v, error = %v, want %v", got, err, want)
+	}
+
+	wantStatuses := []Status{{
+		Request: Request{
+			Method:  History,
+			Base:    "EUR",
+			Symbols: []string{"TRY"},
+			StartAt: date(2020, 12, 22),
+			EndAt:   date(2020, 12, 29),
+		},
+		Offline: true,
+		Stale:   true,
+		Date:    date(2020, 12, 24),
+		Age:     26 * time.Hour,
+		Err:     errNetwork,
+	}}
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Errorf("History() statuses = %+v, want %+v", statuses, wantStatuses)
+	}
+}
+
+func TestProvider_Fx(t *testing.T) {
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC))
+	upstream := gexctest.NewProvider("EUR").FailWith(gexctest.Latest, errNetwork)
+	p := New(upstream, testStore(t, date(2020, 12, 24)), WithClock(clock), WithMaxStaleness(24*time.Hour))
+
+	fx := gexctest.NewFx(p, gexc.WithClock(clock))
+	_, err := fx.Convert(10, "EUR", "TRY")
+
+	var staleErr *StaleError
+	if !errors.As(err, &staleErr) || !errors.Is(err, gexc.ErrClientFailed) || !errors.Is(err, errNetwork) {
+		t.Errorf("Convert() error = %v, want *StaleError", err)
+	}
+
+	upstream.FailWith(gexctest.History, errNetwork)
+	_, err = fx.BasedOn("EUR").Against("TRY").From(date(2020, 12, 21)).Until(date(2020, 12, 29))
+
+	staleErr = nil
+	if !errors.As(err, &staleErr) || !errors.Is(err, gexc.ErrClientFailed) || !errors.Is(err, errNetwork) {
+		t.Errorf("Until() error = %v, want *StaleError", err)
+	}
+}
+
+func TestProvider_ArchiveBase(t *testing.T) {
+	clock := gtime.NewManualClock(time.Date(2020, 12, 24, 17, 0, 0, 0, time.UTC))
+	upstream := gexctest.NewProvider("EUR").
+		FailWith(gexctest.Latest, errNetwork).
+		FailWith(gexctest.SingleDate, errNetwork).
+		FailWith(gexctest.History, errNetwork)
+	p := New(upstream, testStore(t, date(2020, 12, 23), date(2020, 12, 24)), WithClock(clock))
+
+	fx := gexctest.NewFx(p, gexc.WithClock(clock))
+	for _, from := range []string{"EUR", "USD"} {
+		if _, err := fx.Convert(100, from, "TRY"); err != nil {
+			t.Errorf("Convert() from %v error = %v", from, err)
+		}
+	}
+
+	got, err := fx.Convert(120, "USD", "TRY")
+	if err != nil || math.Abs(got-1000) > 1e-9 {
+		t.Errorf("Convert() got = %v, error = %v, want 1000", got, err)
+	}
+
+	history, err := p.History(gexc.HistoryParams{
+		StartAt: gtime.NewGexc(date(2020, 12, 23)),
+		EndAt:   gtime.NewGexc(date(2020, 12, 24)),
+		Base:    "USD",
+		Symbols: []string{"try", "EUR"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := types.TimeRateItem{
+		"2020-12-23": {"TRY": 9 / 1.2, "EUR": 1 / 1.2},
+		"2020-12-24": {"TRY": 10 / 1.2, "EUR": 1 / 1.2},
+	}
+	if history.Base != "USD" || !reflect.DeepEqual(history.Rates, want) {
+		t.Errorf("History() got = %+v, want %v", history, want)
+	}
+
+	_, err = p.Latest(gexc.LatestParams{Base: "GBP"})
+	if !errors.Is(err, errNetwork) || !strings.Contains(err.Error(), "base GBP is not supported") {
+		t.Errorf("Latest() error = %v, want %v for GBP", err, errNetwork)
+	}
+}
+
+func TestProvider_HistoryWindows(t *testing.T) {
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 17, 0, 0, 0, time.UTC))
+	upstream := gexctest.NewProvider("EUR").FailWith(gexctest.History, errNetwork)
+	archive := testStore(t, date(2020, 12, 21), date(2020, 12, 22), date(2020, 12, 24), date(2020, 12, 28))
+
+	var mu sync.Mutex
+	served := make(map[time.Time]time.Time)
+	p := New(upstream, archive, WithClock(clock), WithObserver(func(status Status) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		served[status.Request.StartAt] = status.Date
+	}))
+
+	fx := gexctest.NewFx(p, gexc.WithClock(clock), gexc.WithHistoryWindow(3), gexc.WithConcurrency(3))
+	if _, err := fx.BasedOn("EUR").Against("TRY").From(date(2020, 12, 21)).Until(date(2020, 12, 29)); err != nil {
+		t.Fatalf("Until() error = %v", err)
+	}
+
+	//the statuses of the concurrent windows are told apart by their requests
+	want := map[time.Time]time.Time{
+		date(2020, 12, 21): date(2020, 12, 22),
+		date(2020, 12, 24): date(2020, 12, 24),
+		date(2020, 12, 27): date(2020, 12, 28),
+	}
+
+	if !reflect.DeepEqual(served, want) {
+		t.Errorf("Until() served = %v, want %v", served, want)
+	}
+}
@@ -0,0 +1,95 @@
+package gexc
+
+import (
+	gtime "github.com/fufuceng/gexc/time"
+	"time"
+)
+
+const (
+	defaultConcurrency   = 4
+	defaultHistoryWindow = 365
+)
+
+//Option customizes Fx while it is created by New
+type Option func(f *Fx)
+
+//WithConcurrency sets the maximum number of requests
+//that are sent to the api at the same time.
+//Values smaller than 1 are ignored.
+func WithConcurrency(n int) Option {
+	return func(f *Fx) {
+		if n > 0 {
+			f.concurrency = n
+		}
+	}
+}
+
+//WithHistoryWindow sets the maximum number of days
+//that are fetched with a single history request.
+//Longer ranges are split into windows of this size.
+//Values smaller than 1 are ignored.
+func WithHistoryWindow(days int) Option {
+	return func(f *Fx) {
+		if days > 0 {
+			f.historyWindow = days
+		}
+	}
+}
+
+//WithCalendar sets the publication calendar that is used to
+//resolve the dates that rates are not published on.
+//Default is gtime.TargetCalendar.
+func WithCalendar(calendar gtime.Calendar) Option {
+	return func(f *Fx) {
+		f.calendar = calendar
+	}
+}
+
+//WithClock sets the clock that tells the current time, e.g.
+//a gtime.ManualClock in tests. Default is gtime.SystemClock.
+//Nil clock is ignored.
+func WithClock(clock gtime.Clock) Option {
+	return func(f *Fx) {
+		if clock != nil {
+			f.clock = clock
+		}
+	}
+}
+
+func (f *Fx) workers() int {
+	if f.concurrency < 1 {
+		return defaultConcurrency
+	}
+
+	return f.concurrency
+}
+
+func (f *Fx) window() int {
+	if f.historyWindow < 1 {
+		return defaultHistoryWindow
+	}
+
+	return f.historyWindow
+}
+
+//Clock returns the clock of Fx, see WithClock
+func (f *Fx) Clock() gtime.Clock {
+	if f.clock == nil {
+		return gtime.SystemClock{}
+	}
+
+	return f.clock
+}
+
+//Calendar returns the publication calendar of Fx, see WithCalendar
+func (f *Fx) Calendar() gtime.Calendar {
+	if f.calendar == nil {
+		return gtime.TargetCalendar{}
+	}
+
+	return f.calendar
+}
+
+func (f *Fx) now() time.Time {
+	return f.Clock().Now()
+}
@@ -0,0 +1,238 @@
+package gexc
+
+import (
+	"fmt"
+	"github.com/fufuceng/gexc/response"
+	"strings"
+)
+
+//SpreadUnit is the unit of the spread values
+type SpreadUnit int
+
+const (
+	//Percent spreads are relative to the rate, 0.5 means 0.5%
+	Percent SpreadUnit = iota
+	//Pips spreads are absolute, a pip is 0.0001 of the quote
+	//currency or 0.01 for the currencies listed in pipSizes
+	Pips
+)
+
+var pipSizes = map[string]float64{
+	"JPY": 0.01,
+	"HUF": 0.01,
+	"ISK": 0.01,
+	"KRW": 0.01,
+	"IDR": 0.01,
+}
+
+//Spread is the margin that is applied to the mid rate of a pair.
+//Buy is applied when the customer buys the base currency of the pair
+//and Sell is applied when the customer sells it.
+type Spread struct {
+	Unit SpreadUnit
+	Buy  float64
+	Sell float64
+}
+
+//Margin is a spread that is the same whichever currency the customer buys
+type Margin struct {
+	Unit  SpreadUnit
+	Value float64
+}
+
+//Pricing configures the customer rates of the conversions.
+//Spread of a conversion from X to Y is looked up in order:
+//pair "X/Y" with Sell, pair "Y/X" with Buy, currency Y with Buy,
+//currency X with Sell and at last Default. Keys are upper case
+//codes, WithPricing upper-cases them.
+type Pricing struct {
+	//Default is applied to the conversions that have no spread
+	Default Margin
+	//Pairs are keyed by "BASE/QUOTE", e.g. "EUR/TRY"
+	Pairs map[string]Spread
+	//Currencies are keyed by currency code
+	Currencies map[string]Spread
+	//Fees are fixed fees keyed by the currency that the customer sells.
+	//They are charged in that currency before the conversion.
+	Fees map[string]float64
+}
+
+//WithPricing applies the pricing to the conversions that return
+//details, like At, Latest and ConvertBatch. Their results contain
+//both mid and customer rates. Keys of the pricing are upper-cased.
+//If the pricing is invalid, those conversions fail with the error
+//of Validate.
+func WithPricing(pricing Pricing) Option {
+	return func(f *Fx) {
+		normalized := pricing.normalize()
+		f.pricing = &normalized
+		f.pricingErr = pricing.Validate()
+	}
+}
+
+//Validate returns an error wrapping ErrInvalidParameter if any spread
+//or fee of the pricing is negative, a pair is not keyed like "EUR/TRY"
+//or two keys differ only by case
+func (p Pricing) Validate() error {
+	if p.Default.Value < 0 {
+		return fmt.Errorf("%w: default margin should not be negative", ErrInvalidParameter)
+	}
+
+	pairs := make(caseless, len(p.Pairs))
+	for pair, spread := range p.Pairs {
+		if codes := strings.Split(pair, "/"); len(codes) != 2 || codes[0] == "" || codes[1] == "" {
+			return fmt.Errorf("%w: pair %v should be like EUR/TRY", ErrInvalidParameter, pair)
+		}
+
+		if err := pairs.add(pair); err != nil {
+			return err
+		}
+
+		if spread.Buy < 0 || spread.Sell < 0 {
+			return fmt.Errorf("%w: spreads of pair %v should not be negative", ErrInvalidParameter, pair)
+		}
+	}
+
+	currencies := make(caseless, len(p.Currencies))
+	for currency, spread := range p.Currencies {
+		if err := currencies.add(currency); err != nil {
+			return err
+		}
+
+		if spread.Buy < 0 || spread.Sell < 0 {
+			return fmt.Errorf("%w: spreads of currency %v should not be negative", ErrInvalidParameter, currency)
+		}
+	}
+
+	fees := make(caseless, len(p.Fees))
+	for currency, fee := range p.Fees {
+		if err := fees.add(currency); err != nil {
+			return err
+		}
+
+		if fee < 0 {
+			return fmt.Errorf("%w: fee of %v should not be negative", ErrInvalidParameter, currency)
+		}
+	}
+
+	return nil
+}
+
+//caseless is a set of the keys that are matched regardless of case
+type caseless map[string]string
+
+func (c caseless) add(key string) error {
+	upper := strings.ToUpper(key)
+	if other, ok := c[upper]; ok {
+		return fmt.Errorf("%w: keys %v and %v are the same", ErrInvalidParameter, other, key)
+	}
+
+	c[upper] = key
+	return nil
+}
+
+//normalize returns a copy of the pricing with upper case keys
+func (p Pricing) normalize() Pricing {
+	normalized := Pricing{Default: p.Default}
+	if p.Pairs != nil {
+		normalized.Pairs = make(map[string]Spread, len(p.Pairs))
+		for pair, spread := range p.Pairs {
+			normalized.Pairs[strings.ToUpper(pair)] = spread
+		}
+	}
+
+	if p.Currencies != nil {
+		normalized.Currencies = make(map[string]Spread, len(p.Currencies))
+		for currency, spread := range p.Currencies {
+			normalized.Currencies[strings.ToUpper(currency)] = spread
+		}
+	}
+
+	if p.Fees != nil {
+		normalized.Fees = make(map[string]float64, len(p.Fees))
+		for currency, fee := range p.Fees {
+			normalized.Fees[strings.ToUpper(currency)] = fee
+		}
+	}
+
+	return normalized
+}
+
+//CustomerRate calculates the rate that the customer gets
+//while converting from one currency to another
+func (p Pricing) CustomerRate(mid float64, from, to string) float64 {
+	if spread, ok := p.Pairs[from+"/"+to]; ok {
+		return spread.narrow(mid, to, spread.Sell)
+	}
+
+	if spread, ok := p.Pairs[to+"/"+from]; ok {
+		return 1 / spread.widen(1/mid, from, spread.Buy)
+	}
+
+	if spread, ok := p.Currencies[to]; ok {
+		return spread.narrow(mid, to, spread.Buy)
+	}
+
+	if spread, ok := p.Currencies[from]; ok {
+		return spread.narrow(mid, to, spread.Sell)
+	}
+
+	return Spread{Unit: p.Default.Unit}.narrow(mid, to, p.Default.Value)
+}
+
+//narrow decreases the rate by the spread value
+func (s Spread) narrow(rate float64, quote string, value float64) float64 {
+	if s.Unit == Pips {
+		return rate - value*pipSize(quote)
+	}
+
+	return rate * (1 - value/100)
+}
+
+//widen increases the rate by the spread value
+func (s Spread) widen(rate float64, quote string, value float64) float64 {
+	if s.Unit == Pips {
+		return rate + value*pipSize(quote)
+	}
+
+	return rate * (1 + value/100)
+}
+
+func pipSize(currency string) float64 {
+	if size, ok := pipSizes[currency]; ok {
+		return size
+	}
+
+	return 0.0001
+}
+
+//price fills customer rate, fee and result of the conversion
+func (f *Fx) price(conversion *response.Conversion) error {
+	if f.pricing == nil {
+		conversion.CustomerRate = conversion.Rate
+		conversion.Result = conversion.Amount * conversion.Rate
+		return nil
+	}
+
+	if f.pricingErr != nil {
+		return f.pricingErr
+	}
+
+	fee := f.pricing.Fees[conversion.From]
+	if conversion.Amount < fee {
+		return fmt.Errorf("%w: amount should not be smaller than the fee %v %v",
+			ErrInvalidParameter, fee, conversion.From)
+	}
+
+	customerRate := f.pricing.CustomerRate(conversion.Rate, conversion.From, conversion.To)
+	if !(customerRate > 0) {
+		return fmt.Errorf("%w: spread of %v/%v leaves customer rate %v of mid rate %v",
+			ErrInvalidParameter, conversion.From, conversion.To, customerRate, conversion.Rate)
+	}
+
+	conversion.CustomerRate = customerRate
+	conversion.Fee = fee
+	conversion.Result = (conversion.Amount - fee) * conversion.CustomerRate
+
+	return nil
+}
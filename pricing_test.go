@@ -0,0 +1,164 @@
+package gexc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPricing_CustomerRate(t *testing.T) {
+	pricing := Pricing{
+		Default: Margin{Unit: Percent, Value: 0.5},
+		Pairs: map[string]Spread{
+			"EUR/TRY": {Unit: Percent, Buy: 1, Sell: 2},
+			"EUR/USD": {Unit: Pips, Buy: 10, Sell: 20},
+		},
+		Currencies: map[string]Spread{
+			"JPY": {Unit: Pips, Buy: 50, Sell: 30},
+			"GBP": {Unit: Percent, Buy: 1, Sell: 2},
+		},
+	}
+
+	tests := []struct {
+		name string
+		mid  float64
+		from string
+		to   string
+		want float64
+	}{
+		{name: "should apply sell spread of pair if customer sells base", mid: 8, from: "EUR", to: "TRY", want: 7.84},
+		{name: "should apply buy spread of pair if customer buys base", mid: 0.125, from: "TRY", to: "EUR", want: 1 / 8.08},
+		{name: "should apply pips to the quote currency", mid: 1.5, from: "EUR", to: "USD", want: 1.498},
+		{name: "should apply pips to the inverted pair", mid: 0.5, from: "USD", to: "EUR", want: 1 / 2.001},
+		{name: "should use pip size of the currency", mid: 104, from: "USD", to: "JPY", want: 103.5},
+		{name: "should apply sell spread of sold currency", mid: 16, from: "GBP", to: "TRY", want: 15.68},
+		{name: "should apply default margin", mid: 10, from: "USD", to: "TRY", want: 9.95},
+		{name: "should apply default margin in both directions", mid: 0.1, from: "TRY", to: "USD", want: 0.0995},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pricing.CustomerRate(tt.mid, tt.from, tt.to); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CustomerRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFx_ConversionWithPricing(t *testing.T) {
+	pricing := Pricing{
+		Pairs: map[string]Spread{"EUR/TRY": {Unit: Percent, Sell: 1}},
+		Fees:  map[string]float64{"EUR": 2},
+	}
+
+	tests := []struct {
+		name             string
+		pricing          *Pricing
+		amount           float64
+		wantRate         float64
+		wantCustomerRate float64
+		wantFee          float64
+		wantResult       float64
+		wantErr          error
+	}{
+		{
+			name:             "should return mid and customer rates and deduct the fee",
+			amount:           102,
+			wantRate:         8,
+			wantCustomerRate: 7.92,
+			wantFee:          2,
+			wantResult:       792,
+		},
+		{
+			name:    "should raise an error if amount is smaller than the fee",
+			amount:  1,
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "should raise an error if the spread leaves no customer rate",
+			pricing: &Pricing{Pairs: map[string]Spread{"EUR/TRY": {Unit: Percent, Sell: 100}}},
+			amount:  100,
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "should raise an error if pips make the customer rate negative",
+			pricing: &Pricing{Default: Margin{Unit: Pips, Value: 100000}},
+			amount:  100,
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name: "should match lower case keys",
+			pricing: &Pricing{
+				Pairs: map[string]Spread{"eur/try": {Unit: Percent, Sell: 1}},
+				Fees:  map[string]float64{"eur": 2},
+			},
+			amount:           102,
+			wantRate:         8,
+			wantCustomerRate: 7.92,
+			wantFee:          2,
+			wantResult:       792,
+		},
+		{
+			name:    "should raise an error for negative spreads",
+			pricing: &Pricing{Currencies: map[string]Spread{"TRY": {Unit: Percent, Buy: -1}}},
+			amount:  100,
+			wantErr: ErrInvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pricing
+			if tt.pricing != nil {
+				p = *tt.pricing
+			}
+
+			f := newFxWithClient(&batchTestClient{}, WithPricing(p))
+
+			got, err := f.Amount(tt.amount).From("EUR").Latest().To("TRY")
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("To() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Rate != tt.wantRate || math.Abs(got.CustomerRate-tt.wantCustomerRate) > 1e-9 ||
+				got.Fee != tt.wantFee || math.Abs(got.Result-tt.wantResult) > 1e-9 {
+				t.Errorf("To() got = %v", got)
+			}
+
+			if got.Date.String() != "2020-12-29" || !got.RequestedDate.IsZero() {
+				t.Errorf("To() dates = %v, %v", got.Date, got.RequestedDate)
+			}
+		})
+	}
+}
+
+func TestPricing_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pricing Pricing
+		wantErr bool
+	}{
+		{name: "should accept zero pricing", pricing: Pricing{}},
+		{name: "should reject negative default margin", pricing: Pricing{Default: Margin{Value: -0.5}}, wantErr: true},
+		{name: "should reject negative pair spreads", pricing: Pricing{Pairs: map[string]Spread{"EUR/TRY": {Buy: -1}}}, wantErr: true},
+		{name: "should reject negative fees", pricing: Pricing{Fees: map[string]float64{"EUR": -2}}, wantErr: true},
+		{name: "should reject invalid pairs", pricing: Pricing{Pairs: map[string]Spread{"EURTRY": {Buy: 1}}}, wantErr: true},
+		{
+			name:    "should reject keys that differ by case only",
+			pricing: Pricing{Currencies: map[string]Spread{"try": {Buy: 1}, "TRY": {Buy: 2}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.pricing.Validate(); (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidParameter)) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package gexc
+
+import (
+	"fmt"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+//Provider is the source of the rates that Fx uses.
+//Default provider is the client of exchangeratesapi.io.
+type Provider = openex.Client
+
+type (
+	LatestParams     = openex.LatestParams
+	SingleDateParams = openex.SingleDateParams
+	HistoryParams    = openex.HistoryParams
+)
+
+//WithProvider replaces the default provider.
+//Nil provider is ignored.
+func WithProvider(provider Provider) Option {
+	return func(f *Fx) {
+		if provider != nil {
+			f.openexClient = provider
+		}
+	}
+}
+
+//Provider returns the provider of Fx, see WithProvider
+func (f *Fx) Provider() Provider {
+	return f.openexClient
+}
+
+//WithEndpoint makes the default provider send its requests to
+//the given url instead of exchangeratesapi.io, e.g. a mirror or a
+//test server. Path of the url is the prefix of the endpoints. If the
+//url is invalid, the requests fail with the error of ParseEndpoint.
+func WithEndpoint(rawURL string) Option {
+	option, err := ParseEndpoint(rawURL)
+	if err != nil {
+		return func(f *Fx) {
+			f.clientErr = err
+		}
+	}
+
+	return option
+}
+
+//ParseEndpoint returns the option of WithEndpoint, or an error
+//wrapping ErrInvalidParameter if the url is not an absolute
+//http or https url without query and fragment
+func ParseEndpoint(rawURL string) (Option, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid endpoint %q: %v", ErrInvalidParameter, rawURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("%w: invalid endpoint %q", ErrInvalidParameter, rawURL)
+	}
+
+	return func(f *Fx) {
+		f.clientConfig.Protocol = u.Scheme
+		f.clientConfig.BaseUrl = u.Host
+		f.clientConfig.BasePath = strings.TrimSuffix(u.Path, "/")
+		f.clientErr = nil
+	}, nil
+}
+
+//invalidClient is the default provider of Fx whose
+//configuration is invalid, it fails all requests
+type invalidClient struct {
+	err error
+}
+
+func (c invalidClient) Latest(params LatestParams) (*response.SingleDate, error) {
+	return nil, c.err
+}
+
+func (c invalidClient) SingleDate(params SingleDateParams) (*response.SingleDate, error) {
+	return nil, c.err
+}
+
+func (c invalidClient) History(params HistoryParams) (*response.History, error) {
+	return nil, c.err
+}
+
+//WithHTTPClient makes the default provider send its requests
+//with the given client, e.g. to set timeouts or a custom transport.
+//Nil client is ignored.
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fx) {
+		if client != nil {
+			f.clientConfig.HTTPClient = client
+		}
+	}
+}
+
+//WithAccessKey sets the access key that the default provider
+//sends with its requests
+func WithAccessKey(key string) Option {
+	return func(f *Fx) {
+		f.clientConfig.AccessKey = key
+	}
+}
@@ -0,0 +1,76 @@
+package gexc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawURL   string
+		wantErr  bool
+		wantPath string
+	}{
+		{name: "should accept urls without path", rawURL: "http://localhost:8080"},
+		{name: "should keep the path as prefix", rawURL: "https://mirror.local/rates/", wantPath: "/rates"},
+		{name: "should reject urls without scheme", rawURL: "localhost:8080", wantErr: true},
+		{name: "should reject unknown schemes", rawURL: "ftp://mirror.local", wantErr: true},
+		{name: "should reject queries", rawURL: "http://mirror.local?base=EUR", wantErr: true},
+		{name: "should reject invalid urls", rawURL: "http://[::1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option, err := ParseEndpoint(tt.rawURL)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidParameter) {
+					t.Errorf("ParseEndpoint() error = %v, want %v", err, ErrInvalidParameter)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseEndpoint() error = %v", err)
+			}
+
+			f := New(option)
+			if f.clientConfig.BasePath != tt.wantPath {
+				t.Errorf("ParseEndpoint() path = %q, want %q", f.clientConfig.BasePath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestWithEndpoint(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"base":"EUR","date":"2020-12-29","rates":{"TRY":9.5}}`))
+	}))
+	defer server.Close()
+
+	t.Run("should send the requests under the path of the endpoint", func(t *testing.T) {
+		resp, err := New(WithEndpoint(server.URL + "/mirror")).BasedOn("EUR").Against("TRY").Latest()
+		if err != nil || resp.Rates["TRY"] != 9.5 || path != "/mirror/latest" {
+			t.Errorf("Latest() got = %v, error = %v, path = %v", resp, err, path)
+		}
+	})
+
+	t.Run("should fail the requests for invalid endpoints", func(t *testing.T) {
+		_, err := New(WithEndpoint("localhost:8080")).BasedOn("EUR").Against("TRY").Latest()
+		if !errors.Is(err, ErrClientFailed) || !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("Latest() error = %v, want %v", err, ErrInvalidParameter)
+		}
+	})
+
+	t.Run("should use a valid endpoint after an invalid one", func(t *testing.T) {
+		_, err := New(WithEndpoint("localhost:8080"), WithEndpoint(server.URL)).BasedOn("EUR").Against("TRY").Latest()
+		if err != nil || path != "/latest" {
+			t.Errorf("Latest() error = %v, path = %v", err, path)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+//Package quote issues conversion quotes whose rates are
+//locked for a while and redeems them later.
+package quote
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/response"
+	"time"
+)
+
+const defaultTTL = 5 * time.Minute
+
+var (
+	ErrNotFound = errors.New("quote not found")
+)
+
+//ExpiredError is returned if an expired quote is redeemed
+type ExpiredError struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("quote %v expired at %v", e.ID, e.ExpiresAt.Format(time.RFC3339))
+}
+
+//Quote is a conversion whose rates are locked until ExpiresAt
+type Quote struct {
+	ID         string
+	Conversion response.Conversion
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+}
+
+//expired tells whether the quote can not be redeemed at t
+func (q Quote) expired(t time.Time) bool {
+	return !t.Before(q.ExpiresAt)
+}
+
+//Service issues and redeems quotes
+type Service struct {
+	store   Store
+	ttl     time.Duration
+	now     func() time.Time
+	convert func(amount float64, from, to string) (response.Conversion, error)
+}
+
+//Option customizes Service while it is created by NewService
+type Option func(s *Service)
+
+//WithStore sets the store of the quotes, default is MemoryStore
+func WithStore(store Store) Option {
+	return func(s *Service) {
+		s.store = store
+	}
+}
+
+//WithTTL sets how long the issued quotes are valid, default is 5 minutes
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.ttl = ttl
+		}
+	}
+}
+
+//NewService creates a quote service that converts with the latest
+//rates of fx. Pricing of fx is applied to the quotes, see gexc.WithPricing,
+//and the quotes expire by the clock of fx, see gexc.WithClock.
+func NewService(fx *gexc.Fx, options ...Option) *Service {
+	s := &Service{
+		store: NewMemoryStore(),
+		ttl:   defaultTTL,
+		now:   fx.Clock().Now,
+		convert: func(amount float64, from, to string) (response.Conversion, error) {
+			return fx.Amount(amount).From(from).Latest().To(to)
+		},
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+//Issue converts the amount with the latest rates
+//and stores the result as a quote
+func (s *Service) Issue(amount float64, from, to string) (Quote, error) {
+	conversion, err := s.convert(amount, from, to)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Quote{}, err
+	}
+
+	now := s.now()
+	quote := Quote{
+		ID:         id,
+		Conversion: conversion,
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(s.ttl),
+	}
+
+	if err := s.store.Save(quote); err != nil {
+		return Quote{}, err
+	}
+
+	return quote, nil
+}
+
+//Get returns the quote without redeeming it
+func (s *Service) Get(id string) (Quote, error) {
+	return s.store.Get(id)
+}
+
+//Redeem returns the locked conversion of the quote.
+//A quote can be redeemed only once. Returns *ExpiredError
+//if the quote is expired and ErrNotFound if it does not exist.
+func (s *Service) Redeem(id string) (response.Conversion, error) {
+	quote, err := s.store.Take(id)
+	if err != nil {
+		return response.Conversion{}, err
+	}
+
+	if quote.expired(s.now()) {
+		return response.Conversion{}, &ExpiredError{ID: quote.ID, ExpiresAt: quote.ExpiresAt}
+	}
+
+	return quote.Conversion, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate quote id: %v", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,141 @@
+package quote
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/gexctest"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"testing"
+	"time"
+)
+
+func testService(clock *gtime.ManualClock) *Service {
+	provider := gexctest.NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": 8}).
+		SetClock(clock)
+
+	fx := gexctest.NewFx(provider, gexc.WithClock(clock))
+	return NewService(fx, WithTTL(time.Minute))
+}
+
+func TestService_Redeem(t *testing.T) {
+	issuedAt := time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		wait    time.Duration
+		twice   bool
+		issue   bool
+		wantErr error
+	}{
+		{
+			name: "should return the locked conversion before expiry",
+			wait: 59 * time.Second,
+		},
+		{
+			name:    "should raise an expired quote error after expiry",
+			wait:    time.Minute,
+			wantErr: &ExpiredError{},
+		},
+		{
+			name:    "should raise an expired quote error after newer quotes are issued",
+			wait:    10 * time.Minute,
+			issue:   true,
+			wantErr: &ExpiredError{},
+		},
+		{
+			name:    "should not redeem a quote twice",
+			twice:   true,
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := gtime.NewManualClock(issuedAt)
+			s := testService(clock)
+
+			quote, err := s.Issue(100, "EUR", "TRY")
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			if quote.ID == "" || !quote.ExpiresAt.Equal(issuedAt.Add(time.Minute)) || quote.Conversion.Result != 800 {
+				t.Errorf("Issue() got = %v", quote)
+			}
+
+			clock.Advance(tt.wait)
+			if tt.twice {
+				_, _ = s.Redeem(quote.ID)
+			}
+
+			if tt.issue {
+				if _, err := s.Issue(100, "EUR", "TRY"); err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+			}
+
+			got, err := s.Redeem(quote.ID)
+
+			var expired *ExpiredError
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil || got != quote.Conversion {
+					t.Errorf("Redeem() got = %v, error = %v", got, err)
+				}
+			case *ExpiredError:
+				if !errors.As(err, &expired) || expired.ID != quote.ID {
+					t.Errorf("Redeem() error = %v, want *ExpiredError", err)
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Redeem() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestService_Issue(t *testing.T) {
+	s := testService(gtime.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC)))
+
+	if _, err := s.Issue(100, "UNKNOWN", "TRY"); !errors.Is(err, gexc.ErrUnsupportedCurrency) {
+		t.Errorf("Issue() error = %v, want %v", err, gexc.ErrUnsupportedCurrency)
+	}
+
+	first, _ := s.Issue(100, "EUR", "TRY")
+	second, _ := s.Issue(100, "EUR", "TRY")
+	if first.ID == second.ID {
+		t.Errorf("Issue() returned the same id twice: %v", first.ID)
+	}
+
+	got, err := s.Get(first.ID)
+	if err != nil || got != first {
+		t.Errorf("Get() got = %v, error = %v", got, err)
+	}
+}
+
+func TestMemoryStore_Save(t *testing.T) {
+	now := time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+
+	_ = store.Save(Quote{ID: "old", IssuedAt: now, ExpiresAt: now.Add(time.Minute)})
+	_ = store.Save(Quote{ID: "new", IssuedAt: now.Add(2 * time.Minute), ExpiresAt: now.Add(3 * time.Minute)})
+
+	if _, err := store.Get("old"); err != nil {
+		t.Errorf("Save() should keep recently expired quotes, error = %v", err)
+	}
+
+	_ = store.Save(Quote{ID: "newer", IssuedAt: now.Add(62 * time.Minute), ExpiresAt: now.Add(63 * time.Minute)})
+
+	if _, err := store.Get("old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Save() should remove quotes expired for an hour, error = %v", err)
+	}
+
+	for _, id := range []string{"new", "newer"} {
+		if _, err := store.Get(id); err != nil {
+			t.Errorf("Get() error = %v", err)
+		}
+	}
+}
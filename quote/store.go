@@ -0,0 +1,90 @@
+package quote
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	//expiredRetention is how long the expired quotes are kept, so
+	//redeeming them returns *ExpiredError instead of ErrNotFound
+	expiredRetention = time.Hour
+	//pruneInterval is the time between the removals of the expired quotes
+	pruneInterval = time.Minute
+)
+
+//Store keeps the issued quotes until they are redeemed
+type Store interface {
+	Save(quote Quote) error
+	//Get returns the quote without removing it
+	Get(id string) (Quote, error)
+	//Take returns the quote and removes it, so a quote
+	//can not be taken twice. It returns ErrNotFound if
+	//the quote does not exist.
+	Take(id string) (Quote, error)
+}
+
+//MemoryStore keeps the quotes in memory. Quotes are removed an hour
+//after they expire, at most once a minute by the issue times of the
+//saved quotes, so saving does not scan all quotes every time.
+type MemoryStore struct {
+	mu     sync.Mutex
+	quotes map[string]Quote
+	//nextPrune is the issue time that the expired quotes are removed at
+	nextPrune time.Time
+}
+
+//NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{quotes: make(map[string]Quote)}
+}
+
+func (m *MemoryStore) Save(quote Quote) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !quote.IssuedAt.Before(m.nextPrune) {
+		m.prune(quote.IssuedAt)
+		m.nextPrune = quote.IssuedAt.Add(pruneInterval)
+	}
+
+	m.quotes[quote.ID] = quote
+	return nil
+}
+
+func (m *MemoryStore) Get(id string) (Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	quote, ok := m.quotes[id]
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %v", ErrNotFound, id)
+	}
+
+	return quote, nil
+}
+
+func (m *MemoryStore) Take(id string) (Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	quote, ok := m.quotes[id]
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %v", ErrNotFound, id)
+	}
+
+	delete(m.quotes, id)
+	return quote, nil
+}
+
+//prune removes the quotes that are expired for longer than the retention
+func (m *MemoryStore) prune(now time.Time) {
+	for id, q := range m.quotes {
+		if q.ExpiresAt.Add(expiredRetention).Before(now) {
+			delete(m.quotes, id)
+		}
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
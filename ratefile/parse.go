@@ -0,0 +1,358 @@
+package ratefile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Problems that the validation reports
+var (
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrDuplicateDate   = errors.New("duplicate date")
+	ErrInvalidRate     = errors.New("invalid rate")
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrMixedBase       = errors.New("mixed base currencies")
+)
+
+//maxReported is the number of problems that are listed in the error message
+const maxReported = 5
+
+//Problem is an invalid record of a file.
+//Line is zero if the record is not on a line of its own.
+type Problem struct {
+	Line int
+	Err  error
+}
+
+func (p Problem) Error() string {
+	if p.Line == 0 {
+		return p.Err.Error()
+	}
+
+	return fmt.Sprintf("line %d: %v", p.Line, p.Err)
+}
+
+//ValidationError lists all problems of a file
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var messages []string
+	for i, problem := range e.Problems {
+		if i == maxReported {
+			messages = append(messages, fmt.Sprintf("and %d more", len(e.Problems)-maxReported))
+			break
+		}
+
+		messages = append(messages, problem.Error())
+	}
+
+	name := ""
+	if e.File != "" {
+		name = " " + e.File
+	}
+
+	return fmt.Sprintf("%v%v: %v", ErrInvalidFile, name, strings.Join(messages, "; "))
+}
+
+//Is reports ErrInvalidFile and the errors of the problems
+func (e *ValidationError) Is(target error) bool {
+	if target == ErrInvalidFile {
+		return true
+	}
+
+	for _, problem := range e.Problems {
+		if errors.Is(problem.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//row is a rate of a file
+type row struct {
+	line     int
+	date     string
+	base     string
+	currency string
+	rate     float64
+	err      error
+}
+
+//newProvider validates the rows and builds the tables
+func newProvider(rows []row) (*Provider, error) {
+	p := &Provider{tables: make(map[string]types.RateItem)}
+
+	var problems []Problem
+	report := func(r row, format string, args ...interface{}) {
+		problems = append(problems, Problem{Line: r.line, Err: fmt.Errorf(format, args...)})
+	}
+
+	seen := make(map[string]int)
+	for _, r := range rows {
+		switch {
+		case r.err != nil:
+			report(r, "%w", r.err)
+			continue
+		case !knownCurrency(r.base):
+			report(r, "%w: %v", ErrUnknownCurrency, r.base)
+			continue
+		case !knownCurrency(r.currency):
+			report(r, "%w: %v", ErrUnknownCurrency, r.currency)
+			continue
+		case !(r.rate > 0) || math.IsInf(r.rate, 0):
+			report(r, "%w: %v of %v at %v should be positive and finite", ErrInvalidRate, r.rate, r.currency, r.date)
+			continue
+		}
+
+		if p.base == "" {
+			p.base = r.base
+		}
+
+		if r.base != p.base {
+			report(r, "%w: %v and %v", ErrMixedBase, p.base, r.base)
+			continue
+		}
+
+		if r.currency == r.base {
+			continue
+		}
+
+		key := r.date + "/" + r.currency
+		if line, ok := seen[key]; ok {
+			report(r, "%w: %v of %v is also at line %d", ErrDuplicateDate, r.date, r.currency, line)
+			continue
+		}
+
+		seen[key] = r.line
+
+		if p.tables[r.date] == nil {
+			p.tables[r.date] = make(types.RateItem)
+			p.dates = append(p.dates, r.date)
+		}
+
+		p.tables[r.date][r.currency] = r.rate
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	sort.Strings(p.dates)
+	return p, nil
+}
+
+func knownCurrency(code string) bool {
+	_, ok := gexc.CurrencyByCode(code)
+	return ok
+}
+
+//readCSV reads long or wide CSV, decided by the header
+func readCSV(r io.Reader, o options) ([]row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("%w: date column is missing", ErrInvalidFile)
+	}
+
+	_, hasCurrency := columns["currency"]
+	_, hasRate := columns["rate"]
+	long := hasCurrency && hasRate
+
+	var rows []row
+	//records are on their own lines after the header
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		cell := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		base := o.base
+		if _, ok := columns["base"]; ok {
+			base = cell("base")
+		}
+
+		date, dateErr := parseDate(cell("date"), o)
+
+		if long {
+			rows = append(rows, newRow(line, date, dateErr, base, cell("currency"), cell("rate")))
+			continue
+		}
+
+		for i, name := range header {
+			name = strings.TrimSpace(name)
+			if lower := strings.ToLower(name); lower == "date" || lower == "base" || i >= len(record) {
+				continue
+			}
+
+			//missing rates of the wide layout are empty
+			if value := strings.TrimSpace(record[i]); value != "" {
+				rows = append(rows, newRow(line, date, dateErr, base, name, value))
+			}
+		}
+	}
+}
+
+//readJSON reads JSON Lines in long or wide layout or a history document
+func readJSON(r io.Reader, o options) ([]row, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var rows []row
+	for line := 1; ; line++ {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %v", ErrInvalidFile, line, err)
+		}
+
+		if rates, ok := record["rates"].(map[string]interface{}); ok {
+			rows = append(rows, historyRows(record, rates, o)...)
+			continue
+		}
+
+		base := o.base
+		if value, ok := record["base"]; ok {
+			base = text(value)
+		}
+
+		date, dateErr := parseDate(text(record["date"]), o)
+
+		if _, ok := record["currency"]; ok {
+			rows = append(rows, newRow(line, date, dateErr, base, text(record["currency"]), text(record["rate"])))
+			continue
+		}
+
+		names := make([]string, 0, len(record))
+		for name := range record {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if name == "date" || name == "base" || record[name] == nil {
+				continue
+			}
+
+			rows = append(rows, newRow(line, date, dateErr, base, name, text(record[name])))
+		}
+	}
+}
+
+//historyRows reads the rates of a history document
+func historyRows(record, rates map[string]interface{}, o options) []row {
+	base := text(record["base"])
+	if base == "" {
+		base = o.base
+	}
+
+	var rows []row
+	for day, table := range rates {
+		date, dateErr := parseDate(day, options{dateFormat: gtime.GexcLayout})
+
+		items, ok := table.(map[string]interface{})
+		if !ok {
+			rows = append(rows, row{date: day, err: fmt.Errorf("%w: rates of %v should be an object", ErrInvalidRate, day)})
+			continue
+		}
+
+		for code, value := range items {
+			rows = append(rows, newRow(0, date, dateErr, base, code, text(value)))
+		}
+	}
+
+	//keep the problems in a stable order
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].date != rows[j].date {
+			return rows[i].date < rows[j].date
+		}
+
+		return rows[i].currency < rows[j].currency
+	})
+
+	return rows
+}
+
+func newRow(line int, date string, dateErr error, base, currency, rate string) row {
+	r := row{
+		line:     line,
+		date:     date,
+		base:     strings.ToUpper(strings.TrimSpace(base)),
+		currency: strings.ToUpper(strings.TrimSpace(currency)),
+		err:      dateErr,
+	}
+
+	if r.err != nil {
+		return r
+	}
+
+	value, err := strconv.ParseFloat(rate, 64)
+	if err != nil {
+		r.err = fmt.Errorf("%w: %q of %v at %v", ErrInvalidRate, rate, r.currency, date)
+	}
+
+	r.rate = value
+	return r
+}
+
+//parseDate converts the date to gtime.GexcLayout
+func parseDate(value string, o options) (string, error) {
+	t, err := time.Parse(o.dateFormat, value)
+	if err != nil {
+		return value, fmt.Errorf("%w: %q", ErrInvalidDate, value)
+	}
+
+	return t.Format(gtime.GexcLayout), nil
+}
+
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
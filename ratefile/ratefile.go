@@ -0,0 +1,225 @@
+//Package ratefile serves rates from CSV and JSON files, e.g. rate tables
+//of a closed period that should be used instead of live data.
+//
+//	provider, err := ratefile.Open("rates.csv")
+//	if err != nil {
+//		...
+//	}
+//
+//	fx := gexc.New(gexc.WithProvider(provider))
+//
+//Files are read in the formats that package export writes: long or wide
+//CSV with a header and JSON Lines. JSON documents in the form of the
+//history response of exchangeratesapi.io are accepted as well. Rates of
+//all dates of a file should have the same base currency.
+package ratefile
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Format is the format of a rate file
+type Format int
+
+const (
+	//CSV files have a header record. Files with currency and rate
+	//columns are read in long layout, others in wide layout.
+	CSV Format = iota
+	//JSON files are JSON Lines in long or wide layout
+	//or a single history document
+	JSON
+)
+
+var (
+	//ErrNoRates is returned for the dates that the file has no rates for
+	ErrNoRates = errors.New("no rates")
+	//ErrInvalidFile is returned for files that cannot be read
+	ErrInvalidFile = errors.New("invalid rate file")
+)
+
+//Option customizes the reading of the files
+type Option func(o *options)
+
+type options struct {
+	base       string
+	dateFormat string
+}
+
+//WithBase sets the base currency of the files without a base column,
+//default is EUR
+func WithBase(code string) Option {
+	return func(o *options) {
+		o.base = code
+	}
+}
+
+//WithDateFormat sets the layout of the dates, default is gtime.GexcLayout
+func WithDateFormat(layout string) Option {
+	return func(o *options) {
+		o.dateFormat = layout
+	}
+}
+
+//Provider serves the rates of a file. Rates of a date are served from
+//the table of that date or the previous date of the file, and Latest
+//serves the last date of the file. It is safe for concurrent use.
+type Provider struct {
+	base   string
+	dates  []string
+	tables map[string]types.RateItem
+}
+
+//Open reads the file of path. Format is decided by the extension,
+//.csv is read as CSV and .json, .jsonl and .ndjson as JSON.
+func Open(path string, opts ...Option) (*Provider, error) {
+	var format Format
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		format = CSV
+	case ".json", ".jsonl", ".ndjson":
+		format = JSON
+	default:
+		return nil, fmt.Errorf("%w: unknown extension of %v", ErrInvalidFile, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	provider, err := Load(file, format, opts...)
+	if err != nil {
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			validation.File = path
+		}
+
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+//Load reads the rates from r. It returns *ValidationError
+//with all problems if the rates are not valid.
+func Load(r io.Reader, format Format, opts ...Option) (*Provider, error) {
+	o := options{base: "EUR", dateFormat: gtime.GexcLayout}
+	for _, option := range opts {
+		option(&o)
+	}
+
+	var rows []row
+	var err error
+
+	switch format {
+	case CSV:
+		rows, err = readCSV(r, o)
+	case JSON:
+		rows, err = readJSON(r, o)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %v", ErrInvalidFile, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newProvider(rows)
+}
+
+func (p *Provider) Latest(params gexc.LatestParams) (*response.SingleDate, error) {
+	if len(p.dates) == 0 {
+		return nil, ErrNoRates
+	}
+
+	return p.singleDate(p.dates[len(p.dates)-1], params.Base, params.Symbols)
+}
+
+func (p *Provider) SingleDate(params gexc.SingleDateParams) (*response.SingleDate, error) {
+	day := params.Date.Format(gtime.GexcLayout)
+
+	//the last date that is not after the requested one
+	i := sort.Search(len(p.dates), func(i int) bool {
+		return p.dates[i] > day
+	})
+
+	if i == 0 {
+		return nil, fmt.Errorf("%w at %v", ErrNoRates, day)
+	}
+
+	return p.singleDate(p.dates[i-1], params.Base, params.Symbols)
+}
+
+func (p *Provider) History(params gexc.HistoryParams) (*response.History, error) {
+	from := params.StartAt.Format(gtime.GexcLayout)
+	until := params.EndAt.Format(gtime.GexcLayout)
+
+	history := &response.History{
+		Base:    params.Base,
+		StartAt: params.StartAt,
+		EndAt:   params.EndAt,
+		Rates:   make(types.TimeRateItem),
+	}
+
+	for _, date := range p.dates {
+		if date < from || date > until {
+			continue
+		}
+
+		table, err := p.table(p.tables[date], params.Base, params.Symbols)
+		if err != nil {
+			return nil, err
+		}
+
+		history.Rates[date] = table
+	}
+
+	return history, nil
+}
+
+//Dates returns the dates of the file in chronological order
+func (p *Provider) Dates() []time.Time {
+	dates := make([]time.Time, len(p.dates))
+	for i, date := range p.dates {
+		dates[i], _ = time.Parse(gtime.GexcLayout, date)
+	}
+
+	return dates
+}
+
+func (p *Provider) singleDate(date, base string, symbols []string) (*response.SingleDate, error) {
+	table, err := p.table(p.tables[date], base, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	published, _ := time.Parse(gtime.GexcLayout, date)
+	return &response.SingleDate{Base: base, Rates: table, Date: gtime.NewGexc(published)}, nil
+}
+
+//table rebases the rates and filters them by symbols
+func (p *Provider) table(rates types.RateItem, base string, symbols []string) (types.RateItem, error) {
+	selected, err := (response.SingleDate{Base: p.base, Rates: rates}).Select(base, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	return selected.Rates, nil
+}
+
+var _ gexc.Provider = (*Provider)(nil)
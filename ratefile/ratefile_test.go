@@ -0,0 +1,255 @@
+package ratefile
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/export"
+	"github.com/fufuceng/gexc/response"
+	"github.com/fufuceng/gexc/types"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testHistory() response.History {
+	return response.History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-12-23": {"TRY": 9.3, "USD": 1.25},
+			"2020-12-24": {"TRY": 9.1, "USD": 1.2},
+			"2020-12-28": {"TRY": 9.0},
+		},
+	}
+}
+
+func TestLoad_ExportedFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		write  func(buf *bytes.Buffer) error
+	}{
+		{
+			name:   "long csv",
+			format: CSV,
+			write: func(buf *bytes.Buffer) error {
+				w := export.NewCSVWriter(buf, export.Long)
+				if err := w.WriteHistory(testHistory()); err != nil {
+					return err
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			name:   "wide csv",
+			format: CSV,
+			write: func(buf *bytes.Buffer) error {
+				w := export.NewCSVWriter(buf, export.Wide)
+				if err := w.WriteHistory(testHistory()); err != nil {
+					return err
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			name:   "long json lines",
+			format: JSON,
+			write: func(buf *bytes.Buffer) error {
+				w := export.NewJSONLinesWriter(buf, export.Long)
+				if err := w.WriteHistory(testHistory()); err != nil {
+					return err
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			name:   "wide json lines",
+			format: JSON,
+			write: func(buf *bytes.Buffer) error {
+				w := export.NewJSONLinesWriter(buf, export.Wide, export.WithColumns(export.ColumnDate, export.ColumnBase))
+				if err := w.WriteHistory(testHistory()); err != nil {
+					return err
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			name:   "history document",
+			format: JSON,
+			write: func(buf *bytes.Buffer) error {
+				_, err := buf.WriteString(`{"base":"EUR","rates":{"2020-12-23":{"TRY":9.3,"USD":1.25},` +
+					`"2020-12-24":{"TRY":9.1,"USD":1.2},"2020-12-28":{"TRY":9.0}}}`)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			provider, err := Load(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			history, err := gexc.New(gexc.WithProvider(provider)).
+				BasedOn("EUR").Against().From(date(2020, 12, 1)).Until(date(2020, 12, 31))
+			if err != nil {
+				t.Fatalf("Until() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(history.Rates, testHistory().Rates) {
+				t.Errorf("Until() got = %v, want %v", history.Rates, testHistory().Rates)
+			}
+		})
+	}
+}
+
+func TestProvider(t *testing.T) {
+	provider, err := Load(strings.NewReader(
+		"date,TRY,USD\n"+
+			"2020-12-23,9.3,1.25\n"+
+			"2020-12-24,9.1,1.2\n"+
+			"2020-12-28,9.6,\n"), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fx := gexc.New(gexc.WithProvider(provider))
+
+	latest, err := fx.BasedOn("EUR").Against("TRY").Latest()
+	if err != nil || latest.Date.String() != "2020-12-28" || latest.Rates["TRY"] != 9.6 {
+		t.Errorf("Latest() got = %v, error = %v", latest, err)
+	}
+
+	conversion, err := fx.ConvertAt(10, "USD", "TRY", date(2020, 12, 26))
+	if err != nil || conversion.Date.String() != "2020-12-24" || conversion.Result != 10*9.1/1.2 {
+		t.Errorf("ConvertAt() got = %v, error = %v", conversion, err)
+	}
+
+	if _, err := fx.BasedOn("EUR").Against("TRY").At(date(2020, 12, 1)); !errors.Is(err, gexc.ErrClientFailed) {
+		t.Errorf("At() error = %v for a date before the file", err)
+	}
+
+	if _, err := fx.BasedOn("USD").Against("TRY").Latest(); err == nil {
+		t.Errorf("Latest() error = nil for a base that is missing at the date")
+	}
+
+	if dates := provider.Dates(); len(dates) != 3 || !dates[0].Equal(date(2020, 12, 23)) {
+		t.Errorf("Dates() got = %v", dates)
+	}
+}
+
+func TestLoad_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		options []Option
+		want    []error
+	}{
+		{
+			name:    "should report duplicate dates",
+			format:  CSV,
+			content: "date,TRY\n2020-12-23,9.3\n2020-12-23,9.4\n",
+			want:    []error{ErrDuplicateDate},
+		},
+		{
+			name:    "should report non-positive and invalid rates",
+			format:  CSV,
+			content: "date,currency,rate\n2020-12-23,TRY,0\n2020-12-24,TRY,-1\n2020-12-28,TRY,abc\n",
+			want:    []error{ErrInvalidRate, ErrInvalidRate, ErrInvalidRate},
+		},
+		{
+			name:    "should report NaN and infinite rates",
+			format:  CSV,
+			content: "date,currency,rate\n2020-12-01,TRY,NaN\n2020-12-02,TRY,+Inf\n2020-12-03,TRY,-Inf\n",
+			want:    []error{ErrInvalidRate, ErrInvalidRate, ErrInvalidRate},
+		},
+		{
+			name:    "should report unknown currencies",
+			format:  JSON,
+			content: `{"date":"2020-12-23","base":"EUR","XYZ":1.5,"TRY":9.3}`,
+			want:    []error{ErrUnknownCurrency},
+		},
+		{
+			name:    "should report mixed bases",
+			format:  CSV,
+			content: "date,base,currency,rate\n2020-12-23,EUR,TRY,9.3\n2020-12-24,USD,TRY,7.5\n",
+			want:    []error{ErrMixedBase},
+		},
+		{
+			name:    "should report invalid dates",
+			format:  CSV,
+			content: "date,TRY\n23.12.2020,9.3\n",
+			want:    []error{ErrInvalidDate},
+		},
+		{
+			name:    "should read dates in the given format",
+			format:  CSV,
+			content: "date,TRY\n23.12.2020,9.3\n",
+			options: []Option{WithDateFormat("02.01.2006")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(tt.content), tt.format, tt.options...)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Load() error = %v", err)
+				}
+
+				return
+			}
+
+			var validation *ValidationError
+			if !errors.As(err, &validation) || !errors.Is(err, ErrInvalidFile) {
+				t.Fatalf("Load() error = %v, want *ValidationError", err)
+			}
+
+			if len(validation.Problems) != len(tt.want) {
+				t.Fatalf("Load() problems = %v, want %v", validation.Problems, tt.want)
+			}
+
+			for i, want := range tt.want {
+				if !errors.Is(validation.Problems[i].Err, want) {
+					t.Errorf("Load() problem %d = %v, want %v", i, validation.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "rates.csv")
+	if err := ioutil.WriteFile(path, []byte("date,TRY\n2020-12-23,9.3\n2020-12-23,9.4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Open(path)
+	if !errors.Is(err, ErrDuplicateDate) || !strings.Contains(err.Error(), path+": line 3:") {
+		t.Errorf("Open() error = %v", err)
+	}
+
+	if _, err := Open(filepath.Join(dir, "rates.xlsx")); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Open() error = %v, want %v", err, ErrInvalidFile)
+	}
+}
@@ -0,0 +1,8 @@
+package response
+
+import "errors"
+
+var (
+	ErrBaseNotFound   = errors.New("new base currency not found in rates")
+	ErrSymbolNotFound = errors.New("symbol not found in rates")
+)
@@ -0,0 +1,148 @@
+package response
+
+import (
+	gtime "github.com/fufuceng/gexc/time"
+	"math"
+	"time"
+)
+
+//FillMethod decides how the rates of the filled dates are calculated
+type FillMethod int
+
+const (
+	//FillEmpty leaves the rates of the filled dates as NaN
+	FillEmpty FillMethod = iota
+	//FillPrevious carries the last observed rate forward
+	FillPrevious
+	//FillLinear interpolates linearly between the surrounding observed rates
+	FillLinear
+)
+
+//PointKind tells where a date of the series comes from
+type PointKind int
+
+const (
+	//Observed dates are returned by the api
+	Observed PointKind = iota
+	//NonPublication dates are filled because the calendar
+	//does not publish any rates on them
+	NonPublication
+	//Missing dates are filled although the calendar
+	//expects rates to be published on them
+	Missing
+)
+
+//Kind returns the kind of the i-th date of the series
+func (s Series) Kind(i int) PointKind {
+	if s.Kinds == nil {
+		return Observed
+	}
+
+	return s.Kinds[i]
+}
+
+//IsSynthetic tells whether the i-th date is filled instead of observed
+func (s Series) IsSynthetic(i int) bool {
+	return s.Kind(i) != Observed
+}
+
+//Fill returns a series that has a date for every calendar day
+//between the first and the last dates of the series.
+//Filled dates are marked by Kinds according to the calendar,
+//nil calendar means gtime.TargetCalendar.
+func (s Series) Fill(calendar gtime.Calendar, method FillMethod) Series {
+	filled := Series{
+		Base:  s.Base,
+		Rates: make(map[string][]float64, len(s.Rates)),
+	}
+
+	if s.Len() == 0 {
+		return filled
+	}
+
+	var positions []int
+	first := truncateDay(s.Dates[0].Time)
+	last := truncateDay(s.Dates[s.Len()-1].Time)
+
+	for date, i := first, 0; !date.After(last); date = date.AddDate(0, 0, 1) {
+		filled.Dates = append(filled.Dates, gtime.NewGexc(date))
+
+		switch {
+		case i < s.Len() && truncateDay(s.Dates[i].Time).Equal(date):
+			filled.Kinds = append(filled.Kinds, s.Kind(i))
+			positions = append(positions, i)
+			i++
+		case gtime.IsPublicationDay(calendar, date):
+			filled.Kinds = append(filled.Kinds, Missing)
+			positions = append(positions, -1)
+		default:
+			filled.Kinds = append(filled.Kinds, NonPublication)
+			positions = append(positions, -1)
+		}
+	}
+
+	for code, rates := range s.Rates {
+		values := nanSlice(filled.Len())
+		for i, position := range positions {
+			if position >= 0 {
+				values[i] = rates[position]
+			}
+		}
+
+		for i, position := range positions {
+			if position < 0 {
+				values[i] = fillValue(values, filled.Dates, i, method)
+			}
+		}
+
+		filled.Rates[code] = values
+	}
+
+	return filled
+}
+
+//Fill converts history to Series and fills its gaps, see Series.Fill
+func (h History) Fill(calendar gtime.Calendar, method FillMethod) (Series, error) {
+	series, err := h.Series()
+	if err != nil {
+		return Series{}, err
+	}
+
+	return series.Fill(calendar, method), nil
+}
+
+//fillValue calculates the value of i-th date by using the values
+//before it, which are already filled, and the observed values after it
+func fillValue(values []float64, dates []gtime.Gexc, i int, method FillMethod) float64 {
+	prev := i - 1
+	for prev >= 0 && math.IsNaN(values[prev]) {
+		prev--
+	}
+
+	if prev < 0 {
+		return math.NaN()
+	}
+
+	switch method {
+	case FillPrevious:
+		return values[prev]
+	case FillLinear:
+		for next := i + 1; next < len(values); next++ {
+			if math.IsNaN(values[next]) {
+				continue
+			}
+
+			elapsed := days(dates[prev].Time, dates[i].Time)
+			total := days(dates[prev].Time, dates[next].Time)
+			return values[prev] + (values[next]-values[prev])*elapsed/total
+		}
+
+		return math.NaN()
+	default:
+		return math.NaN()
+	}
+}
+
+func days(from, until time.Time) float64 {
+	return until.Sub(from).Hours() / 24
+}
@@ -0,0 +1,116 @@
+package response
+
+import (
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+//holidayCalendar is a weekday calendar with additional holidays
+type holidayCalendar map[string]bool
+
+func (h holidayCalendar) IsPublicationDay(t time.Time) bool {
+	return gtime.WeekdayCalendar{}.IsPublicationDay(t) && !h[t.Format(gtime.GexcLayout)]
+}
+
+func TestSeries_Fill(t *testing.T) {
+	//2020-12-24 thursday, 2020-12-25 friday (holiday), 2020-12-28 monday,
+	//2020-12-29 tuesday is missing and 2020-12-30 wednesday
+	history := History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-12-24": {"TRY": 8.0, "USD": 1.2},
+			"2020-12-28": {"TRY": 8.4},
+			"2020-12-30": {"TRY": 9.0, "USD": 1.3},
+		},
+	}
+
+	calendar := holidayCalendar{"2020-12-25": true}
+
+	wantKinds := []PointKind{
+		Observed, NonPublication, NonPublication, NonPublication, Observed, Missing, Observed,
+	}
+
+	tests := []struct {
+		name   string
+		method FillMethod
+		want   map[string][]float64
+	}{
+		{
+			name:   "should leave filled dates empty",
+			method: FillEmpty,
+			want: map[string][]float64{
+				"TRY": {8.0, math.NaN(), math.NaN(), math.NaN(), 8.4, math.NaN(), 9.0},
+				"USD": {1.2, math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), 1.3},
+			},
+		},
+		{
+			name:   "should carry last observed rates forward",
+			method: FillPrevious,
+			want: map[string][]float64{
+				"TRY": {8.0, 8.0, 8.0, 8.0, 8.4, 8.4, 9.0},
+				"USD": {1.2, 1.2, 1.2, 1.2, math.NaN(), 1.2, 1.3},
+			},
+		},
+		{
+			name:   "should interpolate linearly between observed rates",
+			method: FillLinear,
+			want: map[string][]float64{
+				"TRY": {8.0, 8.1, 8.2, 8.3, 8.4, 8.7, 9.0},
+				"USD": {1.2, 1.2 + 0.1/6, 1.2 + 0.2/6, 1.2 + 0.3/6, math.NaN(), 1.2 + 0.5/6, 1.3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := history.Fill(calendar, tt.method)
+			if err != nil {
+				t.Fatalf("Fill() error = %v", err)
+			}
+
+			if got.Len() != 7 || got.Dates[1].String() != "2020-12-25" {
+				t.Errorf("Fill() dates = %v", got.Dates)
+			}
+
+			if !reflect.DeepEqual(got.Kinds, wantKinds) {
+				t.Errorf("Fill() kinds = %v, want %v", got.Kinds, wantKinds)
+			}
+
+			for code, want := range tt.want {
+				if !almostEqualRates(got.Rates[code], want) {
+					t.Errorf("Fill() rates of %v = %v, want %v", code, got.Rates[code], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSeries_IsSynthetic(t *testing.T) {
+	series, _ := testHistory().Series()
+	if series.IsSynthetic(0) {
+		t.Errorf("IsSynthetic() = true for unfilled series")
+	}
+
+	filled := series.Fill(nil, FillPrevious)
+	if !filled.IsSynthetic(1) || filled.IsSynthetic(0) {
+		t.Errorf("IsSynthetic() kinds = %v", filled.Kinds)
+	}
+}
+
+func almostEqualRates(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if math.IsNaN(a[i]) != math.IsNaN(b[i]) || math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,150 @@
+package response
+
+import (
+	"fmt"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"sort"
+	"strings"
+)
+
+//Rebase recalculates the rates against the given currency.
+//Old base is added as a currency and the new one is removed.
+//Returns ErrBaseNotFound if the rate of the new base is missing.
+func (s SingleDate) Rebase(code string) (SingleDate, error) {
+	rates, err := rebaseRates(s.Base, s.Rates, code)
+	if err != nil {
+		return SingleDate{}, fmt.Errorf("%w: %v at %v", err, code, s.Date)
+	}
+
+	return SingleDate{Base: code, Rates: rates, Date: s.Date}, nil
+}
+
+//Rebase recalculates the rates of all dates against the given currency.
+//Old base is added as a currency and the new one is removed. Returns
+//ErrBaseNotFound with the dates that the rate of the new base is missing.
+func (h History) Rebase(code string) (History, error) {
+	rebased := History{
+		Base:    code,
+		StartAt: h.StartAt,
+		EndAt:   h.EndAt,
+		Rates:   make(types.TimeRateItem, len(h.Rates)),
+	}
+
+	var missing []string
+	for date, rates := range h.Rates {
+		var err error
+		rebased.Rates[date], err = rebaseRates(h.Base, rates, code)
+		if err != nil {
+			missing = append(missing, date)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return History{}, fmt.Errorf("%w: %v at %v", ErrBaseNotFound, code, strings.Join(missing, ", "))
+	}
+
+	return rebased, nil
+}
+
+func rebaseRates(base string, rates types.RateItem, code string) (types.RateItem, error) {
+	rebased := make(types.RateItem, len(rates)+1)
+	if code == base {
+		for currency, rate := range rates {
+			rebased[currency] = rate
+		}
+
+		return rebased, nil
+	}
+
+	rate, ok := rates[code]
+	if !ok || rate == 0 {
+		return nil, ErrBaseNotFound
+	}
+
+	for currency, r := range rates {
+		if currency != code {
+			rebased[currency] = r / rate
+		}
+	}
+
+	if base != "" {
+		rebased[base] = 1 / rate
+	}
+
+	return rebased, nil
+}
+
+//Rebase recalculates the rates of the series against the given currency.
+//Old base is added as a currency and the new one is removed. Returns
+//ErrBaseNotFound if the rate of the new base is missing for any date.
+func (s Series) Rebase(code string) (Series, error) {
+	if code == s.Base {
+		return s.slice(0, s.Len()), nil
+	}
+
+	base, ok := s.Rates[code]
+	if !ok {
+		return Series{}, fmt.Errorf("%w: %v", ErrBaseNotFound, code)
+	}
+
+	for i, rate := range base {
+		if math.IsNaN(rate) || rate == 0 {
+			return Series{}, fmt.Errorf("%w: %v at %v", ErrBaseNotFound, code, s.Dates[i])
+		}
+	}
+
+	rebased := s.slice(0, s.Len())
+	rebased.Base = code
+	delete(rebased.Rates, code)
+
+	for _, rates := range rebased.Rates {
+		for i := range rates {
+			rates[i] /= base[i]
+		}
+	}
+
+	if s.Base != "" {
+		old := make([]float64, len(base))
+		for i, rate := range base {
+			old[i] = 1 / rate
+		}
+
+		rebased.Rates[s.Base] = old
+	}
+
+	return rebased, nil
+}
+
+//Select rebases the rates to the given currency and keeps the rates of
+//the symbols only, in the way the api serves them. Empty symbols keep all
+//rates and the base is 1 if it is a symbol. Returns ErrBaseNotFound if the
+//rate of the new base is missing and ErrSymbolNotFound for missing symbols.
+func (s SingleDate) Select(base string, symbols []string) (SingleDate, error) {
+	rebased, err := s.Rebase(base)
+	if err != nil {
+		return SingleDate{}, fmt.Errorf("base %v is not supported: %w", base, err)
+	}
+
+	if len(symbols) == 0 {
+		return rebased, nil
+	}
+
+	filtered := make(types.RateItem, len(symbols))
+	for _, symbol := range symbols {
+		rate, ok := rebased.Rates[symbol]
+		if symbol == base {
+			rate, ok = 1, true
+		}
+
+		if !ok {
+			return SingleDate{}, fmt.Errorf("%w: %v", ErrSymbolNotFound, symbol)
+		}
+
+		filtered[symbol] = rate
+	}
+
+	rebased.Rates = filtered
+	return rebased, nil
+}
@@ -0,0 +1,265 @@
+package response
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"testing"
+)
+
+func TestSeries_Rebase(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  History
+		code     string
+		wantBase string
+		want     map[string][]float64
+		wantErr  error
+	}{
+		{
+			name: "should recalculate rates against new base and add old base",
+			history: History{
+				Base: "EUR",
+				Rates: types.TimeRateItem{
+					"2020-12-28": {"TRY": 9.0, "USD": 1.2},
+					"2020-12-29": {"TRY": 10.0, "USD": 1.25},
+				},
+			},
+			code:     "USD",
+			wantBase: "USD",
+			want: map[string][]float64{
+				"TRY": {7.5, 8.0},
+				"EUR": {1 / 1.2, 0.8},
+			},
+		},
+		{
+			name: "should keep missing rates of other currencies",
+			history: History{
+				Base: "EUR",
+				Rates: types.TimeRateItem{
+					"2020-12-28": {"USD": 1.2},
+					"2020-12-29": {"TRY": 10.0, "USD": 1.25},
+				},
+			},
+			code:     "USD",
+			wantBase: "USD",
+			want: map[string][]float64{
+				"TRY": {math.NaN(), 8.0},
+				"EUR": {1 / 1.2, 0.8},
+			},
+		},
+		{
+			name:     "should return the same series for current base",
+			history:  testHistory(),
+			code:     "EUR",
+			wantBase: "EUR",
+			want: map[string][]float64{
+				"TRY": {8.0, 8.5, 9.0},
+				"USD": {math.NaN(), 1.1, 1.2},
+			},
+		},
+		{
+			name:    "should raise an error if new base is missing at some dates",
+			history: testHistory(),
+			code:    "USD",
+			wantErr: ErrBaseNotFound,
+		},
+		{
+			name:    "should raise an error if new base does not exist",
+			history: testHistory(),
+			code:    "GBP",
+			wantErr: ErrBaseNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := tt.history.Series()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := series.Rebase(tt.code)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("Rebase() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Base != tt.wantBase || len(got.Rates) != len(tt.want) {
+				t.Errorf("Rebase() got = %v %v, want %v %v", got.Base, got.Rates, tt.wantBase, tt.want)
+			}
+
+			for code, want := range tt.want {
+				if !almostEqualRates(got.Rates[code], want) {
+					t.Errorf("Rebase() rates of %v = %v, want %v", code, got.Rates[code], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSingleDate_Rebase(t *testing.T) {
+	tests := []struct {
+		name    string
+		single  SingleDate
+		code    string
+		want    SingleDate
+		wantErr bool
+	}{
+		{
+			name:   "should recalculate rates against new base and add old base",
+			single: SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9.0, "USD": 1.25}},
+			code:   "USD",
+			want:   SingleDate{Base: "USD", Rates: types.RateItem{"TRY": 7.2, "EUR": 0.8}},
+		},
+		{
+			name:   "should replace the rate of old base if it exists",
+			single: SingleDate{Base: "TRY", Rates: types.RateItem{"TRY": 1, "EUR": 0.125}},
+			code:   "EUR",
+			want:   SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 8}},
+		},
+		{
+			name:    "should raise an error if new base does not exist",
+			single:  SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9.0}},
+			code:    "USD",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.single.Rebase(tt.code)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrBaseNotFound)) {
+				t.Fatalf("Rebase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got.Base != tt.want.Base || len(got.Rates) != len(tt.want.Rates) {
+				t.Errorf("Rebase() got = %v, want %v", got, tt.want)
+			}
+
+			for code, rate := range tt.want.Rates {
+				if math.Abs(got.Rates[code]-rate) > 1e-9 {
+					t.Errorf("Rebase() rate of %v = %v, want %v", code, got.Rates[code], rate)
+				}
+			}
+		})
+	}
+}
+
+func TestSingleDate_Select(t *testing.T) {
+	single := SingleDate{Base: "EUR", Rates: types.RateItem{"TRY": 9.0, "USD": 1.25}}
+
+	tests := []struct {
+		name    string
+		base    string
+		symbols []string
+		want    types.RateItem
+		wantErr error
+	}{
+		{
+			name: "should rebase all rates without symbols",
+			base: "USD",
+			want: types.RateItem{"TRY": 7.2, "EUR": 0.8},
+		},
+		{
+			name:    "should keep the rates of the symbols and 1 for the base",
+			base:    "USD",
+			symbols: []string{"TRY", "USD"},
+			want:    types.RateItem{"TRY": 7.2, "USD": 1},
+		},
+		{
+			name:    "should raise an error if a symbol does not exist",
+			base:    "EUR",
+			symbols: []string{"GBP"},
+			wantErr: ErrSymbolNotFound,
+		},
+		{
+			name:    "should raise an error if the base does not exist",
+			base:    "GBP",
+			wantErr: ErrBaseNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := single.Select(tt.base, tt.symbols)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Select() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Base != tt.base || len(got.Rates) != len(tt.want) {
+				t.Errorf("Select() got = %v, want %v", got, tt.want)
+			}
+
+			for code, rate := range tt.want {
+				if math.Abs(got.Rates[code]-rate) > 1e-9 {
+					t.Errorf("Select() rate of %v = %v, want %v", code, got.Rates[code], rate)
+				}
+			}
+		})
+	}
+}
+
+func TestHistory_Rebase(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    History
+		code       string
+		want       types.TimeRateItem
+		wantErrMsg string
+	}{
+		{
+			name: "should rebase rates of all dates",
+			history: History{
+				Base: "EUR",
+				Rates: types.TimeRateItem{
+					"2020-12-28": {"TRY": 9.0, "USD": 1.2},
+					"2020-12-29": {"TRY": 10.0, "USD": 1.25},
+				},
+			},
+			code: "USD",
+			want: types.TimeRateItem{
+				"2020-12-28": {"TRY": 7.5, "EUR": 1 / 1.2},
+				"2020-12-29": {"TRY": 8.0, "EUR": 0.8},
+			},
+		},
+		{
+			name:       "should report the dates that new base is missing",
+			history:    testHistory(),
+			code:       "USD",
+			wantErrMsg: "new base currency not found in rates: USD at 2020-12-24",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.history.Rebase(tt.code)
+			if tt.wantErrMsg != "" {
+				if err == nil || err.Error() != tt.wantErrMsg || !errors.Is(err, ErrBaseNotFound) {
+					t.Errorf("Rebase() error = %v, want %v", err, tt.wantErrMsg)
+				}
+				return
+			}
+
+			if err != nil || got.Base != tt.code || len(got.Rates) != len(tt.want) {
+				t.Fatalf("Rebase() got = %v, error = %v", got, err)
+			}
+
+			for date, rates := range tt.want {
+				for code, rate := range rates {
+					if math.Abs(got.Rates[date][code]-rate) > 1e-9 || len(got.Rates[date]) != len(rates) {
+						t.Errorf("Rebase() rates of %v = %v, want %v", date, got.Rates[date], rates)
+					}
+				}
+			}
+		})
+	}
+}
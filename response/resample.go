@@ -0,0 +1,133 @@
+package response
+
+import (
+	"fmt"
+	gtime "github.com/fufuceng/gexc/time"
+	"math"
+	"time"
+)
+
+//Period is the length of the intervals that the series is resampled into
+type Period int
+
+const (
+	//Weekly periods start on mondays
+	Weekly Period = iota + 1
+	Monthly
+	Quarterly
+	Yearly
+)
+
+//Aggregate is the summary of the rates of a currency in a period
+type Aggregate struct {
+	Average float64
+	First   float64
+	Last    float64
+	Min     float64
+	Max     float64
+	Count   int
+}
+
+//PeriodRates is the summary of the rates in a period.
+//Start and End are the first and the last days of the period.
+type PeriodRates struct {
+	Start gtime.Gexc
+	End   gtime.Gexc
+	Rates map[string]Aggregate
+}
+
+//Resample groups the observed rates of the series into periods
+//and summarizes them for each currency. Rates are grouped by their
+//publication dates, so a rate of the first day of a month is in that
+//month in any location. The location only sets the location of Start
+//and End of the periods, nil location means UTC. Filled dates and
+//missing rates are skipped.
+func (s Series) Resample(period Period, location *time.Location) ([]PeriodRates, error) {
+	var result []PeriodRates
+
+	for i, date := range s.Dates {
+		if s.IsSynthetic(i) {
+			continue
+		}
+
+		//dates are publication days, converting them to the location
+		//as instants would move them to the previous day in the west of UTC
+		year, month, day := date.Date()
+		t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		if location != nil {
+			t = time.Date(year, month, day, 0, 0, 0, 0, location)
+		}
+
+		start, end, err := periodOf(t, period)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result) == 0 || !result[len(result)-1].Start.Equal(start) {
+			result = append(result, PeriodRates{
+				Start: gtime.NewGexc(start),
+				End:   gtime.NewGexc(end),
+				Rates: make(map[string]Aggregate),
+			})
+		}
+
+		rates := result[len(result)-1].Rates
+		for code, values := range s.Rates {
+			if math.IsNaN(values[i]) {
+				continue
+			}
+
+			rates[code] = rates[code].add(values[i])
+		}
+	}
+
+	return result, nil
+}
+
+//Resample converts history to Series and resamples it, see Series.Resample
+func (h History) Resample(period Period, location *time.Location) ([]PeriodRates, error) {
+	series, err := h.Series()
+	if err != nil {
+		return nil, err
+	}
+
+	return series.Resample(period, location)
+}
+
+func (a Aggregate) add(rate float64) Aggregate {
+	if a.Count == 0 {
+		return Aggregate{Average: rate, First: rate, Last: rate, Min: rate, Max: rate, Count: 1}
+	}
+
+	a.Average = (a.Average*float64(a.Count) + rate) / float64(a.Count+1)
+	a.Last = rate
+	a.Min = math.Min(a.Min, rate)
+	a.Max = math.Max(a.Max, rate)
+	a.Count++
+
+	return a
+}
+
+//periodOf returns the first and the last days of the period that t belongs to
+func periodOf(t time.Time, period Period) (time.Time, time.Time, error) {
+	year, month, day := t.Date()
+	location := t.Location()
+
+	switch period {
+	case Weekly:
+		offset := (int(t.Weekday()) + 6) % 7
+		start := time.Date(year, month, day-offset, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 0, 6), nil
+	case Monthly:
+		start := time.Date(year, month, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 1, -1), nil
+	case Quarterly:
+		start := time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 3, -1), nil
+	case Yearly:
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(1, 0, -1), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period: %v", period)
+	}
+}
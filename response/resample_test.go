@@ -0,0 +1,247 @@
+package response
+
+import (
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeries_Resample(t *testing.T) {
+	history := History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-12-28": {"TRY": 9.0, "USD": 1.2},
+			"2020-12-29": {"TRY": 8.0},
+			"2020-12-30": {"TRY": 10.0, "USD": 1.4},
+			"2021-01-04": {"TRY": 9.5, "USD": 1.3},
+		},
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//first days of a month and a week, at midnight UTC
+	boundaries := History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-11-30": {"TRY": 9.0},
+			"2020-12-01": {"TRY": 10.0},
+			"2020-12-04": {"TRY": 11.0},
+			"2020-12-07": {"TRY": 12.0},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		history  *History
+		period   Period
+		location *time.Location
+		want     []PeriodRates
+		wantErr  bool
+	}{
+		{
+			name:   "should summarize rates of each month",
+			period: Monthly,
+			want: []PeriodRates{
+				{
+					Start: gtime.NewGexc(day(2020, 12, 1)),
+					End:   gtime.NewGexc(day(2020, 12, 31)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.0, First: 9.0, Last: 10.0, Min: 8.0, Max: 10.0, Count: 3},
+						"USD": {Average: 1.3, First: 1.2, Last: 1.4, Min: 1.2, Max: 1.4, Count: 2},
+					},
+				},
+				{
+					Start: gtime.NewGexc(day(2021, 1, 1)),
+					End:   gtime.NewGexc(day(2021, 1, 31)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.5, First: 9.5, Last: 9.5, Min: 9.5, Max: 9.5, Count: 1},
+						"USD": {Average: 1.3, First: 1.3, Last: 1.3, Min: 1.3, Max: 1.3, Count: 1},
+					},
+				},
+			},
+		},
+		{
+			name:   "should start weeks on mondays",
+			period: Weekly,
+			want: []PeriodRates{
+				{
+					Start: gtime.NewGexc(day(2020, 12, 28)),
+					End:   gtime.NewGexc(day(2021, 1, 3)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.0, First: 9.0, Last: 10.0, Min: 8.0, Max: 10.0, Count: 3},
+						"USD": {Average: 1.3, First: 1.2, Last: 1.4, Min: 1.2, Max: 1.4, Count: 2},
+					},
+				},
+				{
+					Start: gtime.NewGexc(day(2021, 1, 4)),
+					End:   gtime.NewGexc(day(2021, 1, 10)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.5, First: 9.5, Last: 9.5, Min: 9.5, Max: 9.5, Count: 1},
+						"USD": {Average: 1.3, First: 1.3, Last: 1.3, Min: 1.3, Max: 1.3, Count: 1},
+					},
+				},
+			},
+		},
+		{
+			name:   "should calculate quarters",
+			period: Quarterly,
+			want: []PeriodRates{
+				{
+					Start: gtime.NewGexc(day(2020, 10, 1)),
+					End:   gtime.NewGexc(day(2020, 12, 31)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.0, First: 9.0, Last: 10.0, Min: 8.0, Max: 10.0, Count: 3},
+						"USD": {Average: 1.3, First: 1.2, Last: 1.4, Min: 1.2, Max: 1.4, Count: 2},
+					},
+				},
+				{
+					Start: gtime.NewGexc(day(2021, 1, 1)),
+					End:   gtime.NewGexc(day(2021, 3, 31)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.5, First: 9.5, Last: 9.5, Min: 9.5, Max: 9.5, Count: 1},
+						"USD": {Average: 1.3, First: 1.3, Last: 1.3, Min: 1.3, Max: 1.3, Count: 1},
+					},
+				},
+			},
+		},
+		{
+			name:     "should set the location of the boundaries",
+			period:   Yearly,
+			location: newYork,
+			want: []PeriodRates{
+				{
+					Start: gtime.NewGexc(time.Date(2020, 1, 1, 0, 0, 0, 0, newYork)),
+					End:   gtime.NewGexc(time.Date(2020, 12, 31, 0, 0, 0, 0, newYork)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.0, First: 9.0, Last: 10.0, Min: 8.0, Max: 10.0, Count: 3},
+						"USD": {Average: 1.3, First: 1.2, Last: 1.4, Min: 1.2, Max: 1.4, Count: 2},
+					},
+				},
+				{
+					Start: gtime.NewGexc(time.Date(2021, 1, 1, 0, 0, 0, 0, newYork)),
+					End:   gtime.NewGexc(time.Date(2021, 12, 31, 0, 0, 0, 0, newYork)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.5, First: 9.5, Last: 9.5, Min: 9.5, Max: 9.5, Count: 1},
+						"USD": {Average: 1.3, First: 1.3, Last: 1.3, Min: 1.3, Max: 1.3, Count: 1},
+					},
+				},
+			},
+		},
+		{
+			name:     "should keep the first day of the month in its month in the given location",
+			history:  &boundaries,
+			period:   Monthly,
+			location: newYork,
+			want: []PeriodRates{
+				{
+					Start: gtime.NewGexc(time.Date(2020, 11, 1, 0, 0, 0, 0, newYork)),
+					End:   gtime.NewGexc(time.Date(2020, 11, 30, 0, 0, 0, 0, newYork)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 9.0, First: 9.0, Last: 9.0, Min: 9.0, Max: 9.0, Count: 1},
+					},
+				},
+				{
+					Start: gtime.NewGexc(time.Date(2020, 12, 1, 0, 0, 0, 0, newYork)),
+					End:   gtime.NewGexc(time.Date(2020, 12, 31, 0, 0, 0, 0, newYork)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 11.0, First: 10.0, Last: 12.0, Min: 10.0, Max: 12.0, Count: 3},
+					},
+				},
+			},
+		},
+		{
+			name:     "should keep mondays in their week in the given location",
+			history:  &boundaries,
+			period:   Weekly,
+			location: newYork,
+			want: []PeriodRates{
+				{
+					Start: gtime.NewGexc(time.Date(2020, 11, 30, 0, 0, 0, 0, newYork)),
+					End:   gtime.NewGexc(time.Date(2020, 12, 6, 0, 0, 0, 0, newYork)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 10.0, First: 9.0, Last: 11.0, Min: 9.0, Max: 11.0, Count: 3},
+					},
+				},
+				{
+					Start: gtime.NewGexc(time.Date(2020, 12, 7, 0, 0, 0, 0, newYork)),
+					End:   gtime.NewGexc(time.Date(2020, 12, 13, 0, 0, 0, 0, newYork)),
+					Rates: map[string]Aggregate{
+						"TRY": {Average: 12.0, First: 12.0, Last: 12.0, Min: 12.0, Max: 12.0, Count: 1},
+					},
+				},
+			},
+		},
+		{
+			name:    "should raise an error for unknown periods",
+			period:  Period(0),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := history
+			if tt.history != nil {
+				h = *tt.history
+			}
+
+			got, err := h.Resample(tt.period, tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Resample() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Resample() got = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start.Time) || !got[i].End.Equal(tt.want[i].End.Time) {
+					t.Errorf("Resample()[%d] period = %v - %v, want %v - %v",
+						i, got[i].Start, got[i].End, tt.want[i].Start, tt.want[i].End)
+				}
+
+				if !equalAggregates(got[i].Rates, tt.want[i].Rates) {
+					t.Errorf("Resample()[%d] rates = %v, want %v", i, got[i].Rates, tt.want[i].Rates)
+				}
+			}
+		})
+	}
+}
+
+func TestSeries_ResampleSkipsFilledDates(t *testing.T) {
+	series, _ := testHistory().Series()
+
+	got, err := series.Fill(nil, FillPrevious).Resample(Monthly, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Aggregate{Average: 8.5, First: 8.0, Last: 9.0, Min: 8.0, Max: 9.0, Count: 3}
+	if !reflect.DeepEqual(got[0].Rates["TRY"], want) {
+		t.Errorf("Resample() got = %v, want %v", got[0].Rates["TRY"], want)
+	}
+}
+
+func equalAggregates(a, b map[string]Aggregate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for code, want := range b {
+		got := a[code]
+		if !almostEqualRates(
+			[]float64{got.Average, got.First, got.Last, got.Min, got.Max, float64(got.Count)},
+			[]float64{want.Average, want.First, want.Last, want.Min, want.Max, float64(want.Count)},
+		) {
+			return false
+		}
+	}
+
+	return true
+}
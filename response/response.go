@@ -21,3 +21,44 @@ type SingleDate struct {
 	Rates types.RateItem `json:"rates"`
 	Date  time.Gexc      `json:"date"`
 }
+
+//Conversion is representation of the
+//result of the dated conversion functions
+type Conversion struct {
+	Amount float64
+	From   string
+	To     string
+	//Rate is the mid rate of the pair
+	Rate float64
+	//CustomerRate is the rate after the spread is applied.
+	//It is equal to Rate if there is no pricing.
+	CustomerRate float64
+	//Fee is the fixed fee in From currency that is
+	//deducted from the amount before the conversion
+	Fee    float64
+	Result float64
+	//RequestedDate is the date that the conversion is asked for
+	RequestedDate time.Gexc
+	//Date is the publication date of the rates that are used.
+	//It is earlier than RequestedDate on weekends and holidays.
+	Date time.Gexc
+}
+
+//Change is representation of the
+//result of the change function
+type Change struct {
+	Base string
+	//StartAt and EndAt are the publication dates of the compared rates
+	StartAt time.Gexc
+	EndAt   time.Gexc
+	Rates   map[string]RateChange
+}
+
+//RateChange is the move of a currency between two dates.
+//Percent is the change relative to the start, 1.5 means 1.5%.
+type RateChange struct {
+	Start    float64
+	End      float64
+	Absolute float64
+	Percent  float64
+}
@@ -0,0 +1,179 @@
+package response
+
+import (
+	"fmt"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"sort"
+	"time"
+)
+
+//Series is the chronologically ordered form of the History.
+//Rates of each currency are aligned with Dates and
+//missing values are represented as NaN.
+type Series struct {
+	Base  string
+	Dates []gtime.Gexc
+	Rates map[string][]float64
+	//Kinds tells where each date of the series comes from.
+	//It is nil if all dates are observed, see Fill.
+	Kinds []PointKind
+}
+
+//Series converts history to Series.
+//Returns an error if any date of the history can not be parsed.
+func (h History) Series() (Series, error) {
+	series := Series{
+		Base:  h.Base,
+		Dates: make([]gtime.Gexc, 0, len(h.Rates)),
+		Rates: make(map[string][]float64),
+	}
+
+	for date := range h.Rates {
+		t, err := time.Parse(gtime.GexcLayout, date)
+		if err != nil {
+			return Series{}, fmt.Errorf("invalid history date %q: %v", date, err)
+		}
+
+		series.Dates = append(series.Dates, gtime.NewGexc(t))
+	}
+
+	sort.Slice(series.Dates, func(i, j int) bool {
+		return series.Dates[i].Before(series.Dates[j].Time)
+	})
+
+	for i, date := range series.Dates {
+		for code, rate := range h.Rates[date.String()] {
+			rates, ok := series.Rates[code]
+			if !ok {
+				rates = nanSlice(len(series.Dates))
+				series.Rates[code] = rates
+			}
+
+			rates[i] = rate
+		}
+	}
+
+	return series, nil
+}
+
+//Len returns the number of dates in the series
+func (s Series) Len() int {
+	return len(s.Dates)
+}
+
+//Currencies returns the currency codes of the series in alphabetical order
+func (s Series) Currencies() []string {
+	codes := make([]string, 0, len(s.Rates))
+	for code := range s.Rates {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+	return codes
+}
+
+//Each calls fn for every date in chronological order
+//until fn returns false. Missing rates are not included.
+func (s Series) Each(fn func(date gtime.Gexc, rates types.RateItem) bool) {
+	for i, date := range s.Dates {
+		if !fn(date, s.RatesAt(i)) {
+			return
+		}
+	}
+}
+
+//RatesAt returns the rates of the i-th date of the series
+func (s Series) RatesAt(i int) types.RateItem {
+	rates := make(types.RateItem, len(s.Rates))
+	for code, values := range s.Rates {
+		if !math.IsNaN(values[i]) {
+			rates[code] = values[i]
+		}
+	}
+
+	return rates
+}
+
+//Index returns the position of the given date in the series.
+//Only year, month and day of the date are taken into account.
+func (s Series) Index(date time.Time) (int, bool) {
+	day := truncateDay(date)
+	i := s.search(day)
+	if i < len(s.Dates) && truncateDay(s.Dates[i].Time).Equal(day) {
+		return i, true
+	}
+
+	return 0, false
+}
+
+//At returns the rates of the given date
+func (s Series) At(date time.Time) (types.RateItem, bool) {
+	i, ok := s.Index(date)
+	if !ok {
+		return nil, false
+	}
+
+	return s.RatesAt(i), true
+}
+
+//Between returns the part of the series that is
+//between from and until dates, both inclusive
+func (s Series) Between(from, until time.Time) Series {
+	start := s.search(truncateDay(from))
+	end := s.search(truncateDay(until).AddDate(0, 0, 1))
+	if end < start {
+		end = start
+	}
+
+	return s.slice(start, end)
+}
+
+//Currency returns the rates of the given currency in chronological order.
+//Returned slice is aligned with Dates and contains NaN for missing rates.
+func (s Series) Currency(code string) ([]float64, bool) {
+	rates, ok := s.Rates[code]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]float64(nil), rates...), true
+}
+
+func (s Series) search(day time.Time) int {
+	return sort.Search(len(s.Dates), func(i int) bool {
+		return !truncateDay(s.Dates[i].Time).Before(day)
+	})
+}
+
+func (s Series) slice(start, end int) Series {
+	part := Series{
+		Base:  s.Base,
+		Dates: append([]gtime.Gexc{}, s.Dates[start:end]...),
+		Rates: make(map[string][]float64, len(s.Rates)),
+	}
+
+	for code, rates := range s.Rates {
+		part.Rates[code] = append([]float64{}, rates[start:end]...)
+	}
+
+	if s.Kinds != nil {
+		part.Kinds = append([]PointKind{}, s.Kinds[start:end]...)
+	}
+
+	return part
+}
+
+func nanSlice(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+
+	return values
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
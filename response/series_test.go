@@ -0,0 +1,204 @@
+package response
+
+import (
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testHistory() History {
+	return History{
+		Base: "EUR",
+		Rates: types.TimeRateItem{
+			"2020-12-29": {"TRY": 9.0, "USD": 1.2},
+			"2020-12-24": {"TRY": 8.0},
+			"2020-12-28": {"TRY": 8.5, "USD": 1.1},
+		},
+	}
+}
+
+//equalRates compares float slices by treating NaN values as equal
+func equalRates(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if math.IsNaN(a[i]) != math.IsNaN(b[i]) || (!math.IsNaN(a[i]) && a[i] != b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestHistory_Series(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   History
+		wantDates []gtime.Gexc
+		wantRates map[string][]float64
+		wantErr   bool
+	}{
+		{
+			name:    "should order dates and align rates of currencies",
+			history: testHistory(),
+			wantDates: []gtime.Gexc{
+				gtime.NewGexc(day(2020, 12, 24)),
+				gtime.NewGexc(day(2020, 12, 28)),
+				gtime.NewGexc(day(2020, 12, 29)),
+			},
+			wantRates: map[string][]float64{
+				"TRY": {8.0, 8.5, 9.0},
+				"USD": {math.NaN(), 1.1, 1.2},
+			},
+		},
+		{
+			name: "should raise an error if date is invalid",
+			history: History{
+				Rates: types.TimeRateItem{"29-12-2020": {"TRY": 9.0}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.history.Series()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Series() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Dates, tt.wantDates) {
+				t.Errorf("Series() dates = %v, want %v", got.Dates, tt.wantDates)
+			}
+
+			if len(got.Rates) != len(tt.wantRates) {
+				t.Errorf("Series() currencies = %v, want %v", got.Currencies(), len(tt.wantRates))
+			}
+
+			for code, want := range tt.wantRates {
+				if !equalRates(got.Rates[code], want) {
+					t.Errorf("Series() rates of %v = %v, want %v", code, got.Rates[code], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSeries_At(t *testing.T) {
+	series, _ := testHistory().Series()
+
+	tests := []struct {
+		name  string
+		date  time.Time
+		want  types.RateItem
+		want1 bool
+	}{
+		{
+			name:  "should return rates of the date without missing ones",
+			date:  time.Date(2020, 12, 24, 18, 0, 0, 0, time.UTC),
+			want:  types.RateItem{"TRY": 8.0},
+			want1: true,
+		},
+		{
+			name:  "should return false if date does not exist",
+			date:  day(2020, 12, 25),
+			want1: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := series.At(tt.date)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("At() got = %v, want %v", got, tt.want)
+			}
+
+			if got1 != tt.want1 {
+				t.Errorf("At() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestSeries_Between(t *testing.T) {
+	series, _ := testHistory().Series()
+
+	tests := []struct {
+		name      string
+		from      time.Time
+		until     time.Time
+		wantDates []string
+		wantTRY   []float64
+	}{
+		{
+			name:      "should include both ends of the range",
+			from:      day(2020, 12, 24),
+			until:     day(2020, 12, 28),
+			wantDates: []string{"2020-12-24", "2020-12-28"},
+			wantTRY:   []float64{8.0, 8.5},
+		},
+		{
+			name:      "should handle dates that are not in the series",
+			from:      day(2020, 12, 25),
+			until:     day(2021, 1, 10),
+			wantDates: []string{"2020-12-28", "2020-12-29"},
+			wantTRY:   []float64{8.5, 9.0},
+		},
+		{
+			name:      "should return empty series if range is reversed",
+			from:      day(2020, 12, 29),
+			until:     day(2020, 12, 24),
+			wantDates: []string{},
+			wantTRY:   []float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := series.Between(tt.from, tt.until)
+
+			dates := make([]string, 0, got.Len())
+			got.Each(func(date gtime.Gexc, rates types.RateItem) bool {
+				dates = append(dates, date.String())
+				return true
+			})
+
+			if !reflect.DeepEqual(dates, tt.wantDates) {
+				t.Errorf("Between() dates = %v, want %v", dates, tt.wantDates)
+			}
+
+			if try, _ := got.Currency("TRY"); !equalRates(try, tt.wantTRY) {
+				t.Errorf("Between() rates = %v, want %v", try, tt.wantTRY)
+			}
+		})
+	}
+}
+
+func TestSeries_Each(t *testing.T) {
+	series, _ := testHistory().Series()
+
+	var visited []string
+	series.Each(func(date gtime.Gexc, rates types.RateItem) bool {
+		visited = append(visited, date.String())
+		return len(visited) < 2
+	})
+
+	want := []string{"2020-12-24", "2020-12-28"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("Each() visited = %v, want %v", visited, want)
+	}
+}
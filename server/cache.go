@@ -0,0 +1,322 @@
+package server
+
+import (
+	"container/list"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//defaultMaxEntries is the number of the responses that are cached by default
+const defaultMaxEntries = 1000
+
+//lagExpiry is how long the responses of a provider that does not
+//serve the published rates yet are cached
+const lagExpiry = 5 * time.Minute
+
+//Cache is a provider that keeps the responses of another provider.
+//Rates of the days that are already published never change, so they do
+//not expire. Latest rates and the rates of the days that are not published
+//yet expire at the next publication time of the calendar. Responses that
+//lack the published rates, e.g. when the provider lags behind the
+//publication, expire after a few minutes. Responses are not cached
+//if the calendar has no publication day within a year. The number of
+//the responses is bounded, expired responses are removed first and then
+//the least recently used ones. Errors are not cached. It is safe for
+//concurrent use.
+type Cache struct {
+	provider   gexc.Provider
+	clock      gtime.Clock
+	calendar   gtime.Calendar
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	//recent orders the entries from the most recently used
+	recent *list.List
+}
+
+type cacheEntry struct {
+	key        string
+	singleDate *response.SingleDate
+	history    *response.History
+	//expiresAt is zero for the entries that never expire
+	expiresAt time.Time
+}
+
+//CacheOption customizes Cache while it is created by NewCache
+type CacheOption func(c *Cache)
+
+//WithClock sets the clock that decides the expiry of the entries,
+//default is gtime.SystemClock
+func WithClock(clock gtime.Clock) CacheOption {
+	return func(c *Cache) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+//WithCalendar sets the publication calendar of the rates,
+//default is gtime.TargetCalendar
+func WithCalendar(calendar gtime.Calendar) CacheOption {
+	return func(c *Cache) {
+		c.calendar = calendar
+	}
+}
+
+//WithMaxEntries sets the maximum number of the cached responses,
+//default is 1000. Values smaller than 1 are ignored.
+func WithMaxEntries(n int) CacheOption {
+	return func(c *Cache) {
+		if n > 0 {
+			c.maxEntries = n
+		}
+	}
+}
+
+//NewCache creates a cache of the provider
+func NewCache(provider gexc.Provider, options ...CacheOption) *Cache {
+	c := &Cache{
+		provider:   provider,
+		clock:      gtime.SystemClock{},
+		maxEntries: defaultMaxEntries,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+func (c *Cache) Latest(params gexc.LatestParams) (*response.SingleDate, error) {
+	key := cacheKey("latest", params.Base, params.Symbols)
+	if entry, ok := c.get(key); ok {
+		return copySingleDate(entry.singleDate), nil
+	}
+
+	resp, err := c.provider.Latest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt, err := c.latestExpiry(resp.Date.Time); err == nil {
+		c.set(key, cacheEntry{singleDate: copySingleDate(resp), expiresAt: expiresAt})
+	}
+
+	return resp, nil
+}
+
+func (c *Cache) SingleDate(params gexc.SingleDateParams) (*response.SingleDate, error) {
+	key := cacheKey(params.Date.String(), params.Base, params.Symbols)
+	if entry, ok := c.get(key); ok {
+		return copySingleDate(entry.singleDate), nil
+	}
+
+	resp, err := c.provider.SingleDate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt, err := c.expiry(params.Date.Time, time.Time{}, resp.Date.Time); err == nil {
+		c.set(key, cacheEntry{singleDate: copySingleDate(resp), expiresAt: expiresAt})
+	}
+
+	return resp, nil
+}
+
+func (c *Cache) History(params gexc.HistoryParams) (*response.History, error) {
+	key := cacheKey("history/"+params.StartAt.String()+"/"+params.EndAt.String(), params.Base, params.Symbols)
+	if entry, ok := c.get(key); ok {
+		return copyHistory(entry.history), nil
+	}
+
+	resp, err := c.provider.History(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt, err := c.expiry(params.EndAt.Time, params.StartAt.Time, lastDate(resp)); err == nil {
+		c.set(key, cacheEntry{history: copyHistory(resp), expiresAt: expiresAt})
+	}
+
+	return resp, nil
+}
+
+//Len returns the number of the cached responses, including the expired ones
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+//latestExpiry returns the next publication time, or a short expiry
+//if the served rates are older than the last published ones
+func (c *Cache) latestExpiry(served time.Time) (time.Time, error) {
+	now := c.clock.Now()
+	published, err := gtime.LatestPublishedDay(c.calendar, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if served.Before(published) {
+		return now.Add(lagExpiry), nil
+	}
+
+	return gtime.NextPublicationTime(c.calendar, now)
+}
+
+//expiry returns the next publication time if the rates of the date are
+//not published yet. Otherwise it returns zero if the served rates are of
+//the last publication day until the date, and a short expiry if they are
+//older. Publication days before start are not expected in the response.
+func (c *Cache) expiry(date, start, served time.Time) (time.Time, error) {
+	now := c.clock.Now()
+	published, err := gtime.LatestPublishedDay(c.calendar, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if day.After(published) {
+		return gtime.NextPublicationTime(c.calendar, now)
+	}
+
+	expected, err := gtime.LatestPublicationDay(c.calendar, day)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if served.Before(expected) && !expected.Before(start) {
+		return now.Add(lagExpiry), nil
+	}
+
+	return time.Time{}, nil
+}
+
+func (c *Cache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+
+	entry := element.Value.(cacheEntry)
+	if entry.expired(c.clock.Now()) {
+		c.remove(element)
+		return cacheEntry{}, false
+	}
+
+	c.recent.MoveToFront(element)
+	return entry, true
+}
+
+func (c *Cache) set(key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.key = key
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+		return
+	}
+
+	if len(c.entries) >= c.maxEntries {
+		c.prune()
+	}
+
+	for len(c.entries) >= c.maxEntries {
+		c.remove(c.recent.Back())
+	}
+
+	c.entries[key] = c.recent.PushFront(entry)
+}
+
+//prune removes the expired entries
+func (c *Cache) prune() {
+	now := c.clock.Now()
+	for element := c.recent.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(cacheEntry).expired(now) {
+			c.remove(element)
+		}
+
+		element = next
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	c.recent.Remove(element)
+	delete(c.entries, element.Value.(cacheEntry).key)
+}
+
+//lastDate returns the last date of the history, zero if it has no rates
+func lastDate(h *response.History) time.Time {
+	var last time.Time
+	for day := range h.Rates {
+		if date, err := time.Parse(gtime.GexcLayout, day); err == nil && date.After(last) {
+			last = date
+		}
+	}
+
+	return last
+}
+
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+//cacheKey does not depend on the order and the case of the symbols
+func cacheKey(request, base string, symbols []string) string {
+	codes := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		codes[i] = strings.ToUpper(symbol)
+	}
+
+	sort.Strings(codes)
+	return request + "|" + strings.ToUpper(base) + "|" + strings.Join(codes, ",")
+}
+
+//responses are copied, so callers cannot change the cached rates
+func copySingleDate(s *response.SingleDate) *response.SingleDate {
+	cpy := *s
+	cpy.Rates = copyRates(s.Rates)
+	return &cpy
+}
+
+func copyHistory(h *response.History) *response.History {
+	cpy := *h
+	cpy.Rates = make(types.TimeRateItem, len(h.Rates))
+	for date, rates := range h.Rates {
+		cpy.Rates[date] = copyRates(rates)
+	}
+
+	return &cpy
+}
+
+func copyRates(rates types.RateItem) types.RateItem {
+	if rates == nil {
+		return nil
+	}
+
+	cpy := make(types.RateItem, len(rates))
+	for code, rate := range rates {
+		cpy[code] = rate
+	}
+
+	return cpy
+}
+
+var _ gexc.Provider = (*Cache)(nil)
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"github.com/fufuceng/gexc"
+	gtime "github.com/fufuceng/gexc/time"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	date := func(y int, m time.Month, d int) gtime.Gexc {
+		return gtime.NewGexc(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	}
+
+	tests := []struct {
+		name  string
+		fetch func(c *Cache) error
+		//start is the time of the first fetch, default is 12:00 UTC on 2020-12-29
+		start time.Time
+		//lag makes the provider serve older rates
+		lag int
+		//advance moves the clock between the fetches
+		advance   time.Duration
+		wantCalls int
+	}{
+		{
+			name: "should keep published dates without expiry",
+			fetch: func(c *Cache) error {
+				_, err := c.SingleDate(gexc.SingleDateParams{Date: date(2020, 12, 24), Base: "EUR", Symbols: []string{"TRY", "USD"}})
+				return err
+			},
+			advance:   365 * 24 * time.Hour,
+			wantCalls: 1,
+		},
+		{
+			name: "should keep latest rates until the next publication",
+			fetch: func(c *Cache) error {
+				_, err := c.Latest(gexc.LatestParams{Base: "EUR"})
+				return err
+			},
+			advance:   2 * time.Hour,
+			wantCalls: 1,
+		},
+		{
+			name: "should fetch latest rates again after the publication",
+			fetch: func(c *Cache) error {
+				_, err := c.Latest(gexc.LatestParams{Base: "EUR"})
+				return err
+			},
+			advance:   4 * time.Hour,
+			wantCalls: 2,
+		},
+		{
+			name: "should fetch latest rates again if the provider lags",
+			fetch: func(c *Cache) error {
+				_, err := c.Latest(gexc.LatestParams{Base: "EUR"})
+				return err
+			},
+			//latest rates of the provider are of 2020-12-29 until 2020-12-30 15:00 UTC
+			start:     time.Date(2020, 12, 30, 15, 1, 0, 0, time.UTC),
+			advance:   10 * time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name: "should fetch unpublished dates again after the publication",
+			fetch: func(c *Cache) error {
+				_, err := c.SingleDate(gexc.SingleDateParams{Date: date(2020, 12, 29), Base: "EUR"})
+				return err
+			},
+			advance:   4 * time.Hour,
+			wantCalls: 2,
+		},
+		{
+			name: "should keep published history without expiry",
+			fetch: func(c *Cache) error {
+				_, err := c.History(gexc.HistoryParams{StartAt: date(2020, 12, 1), EndAt: date(2020, 12, 28), Base: "EUR"})
+				return err
+			},
+			advance:   365 * 24 * time.Hour,
+			wantCalls: 1,
+		},
+		{
+			name: "should fetch published dates again if the provider lags",
+			fetch: func(c *Cache) error {
+				_, err := c.SingleDate(gexc.SingleDateParams{Date: date(2020, 12, 29), Base: "EUR"})
+				return err
+			},
+			//rates of 2020-12-29 are published but the provider serves 2020-12-28
+			start:     time.Date(2020, 12, 29, 15, 1, 0, 0, time.UTC),
+			lag:       1,
+			advance:   30 * 24 * time.Hour,
+			wantCalls: 2,
+		},
+		{
+			name: "should keep the responses of a lagging provider for a few minutes",
+			fetch: func(c *Cache) error {
+				_, err := c.SingleDate(gexc.SingleDateParams{Date: date(2020, 12, 29), Base: "EUR"})
+				return err
+			},
+			start:     time.Date(2020, 12, 29, 15, 1, 0, 0, time.UTC),
+			lag:       1,
+			advance:   time.Minute,
+			wantCalls: 1,
+		},
+		{
+			name: "should keep older rates of the days that are not publication days",
+			fetch: func(c *Cache) error {
+				_, err := c.SingleDate(gexc.SingleDateParams{Date: date(2020, 12, 27), Base: "EUR"})
+				return err
+			},
+			//2020-12-27 is a Sunday, the rates of 2020-12-24 are the last ones until it
+			lag:       3,
+			advance:   365 * 24 * time.Hour,
+			wantCalls: 1,
+		},
+		{
+			name: "should fetch published history again if the provider lags",
+			fetch: func(c *Cache) error {
+				_, err := c.History(gexc.HistoryParams{StartAt: date(2020, 12, 1), EndAt: date(2020, 12, 28), Base: "EUR"})
+				return err
+			},
+			lag:       4,
+			advance:   10 * time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name: "should not cache errors",
+			fetch: func(c *Cache) error {
+				_, _ = c.Latest(gexc.LatestParams{Base: "USD"})
+				return nil
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//rates of 2020-12-29 are published at 15:00 UTC
+			start := time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC)
+			if !tt.start.IsZero() {
+				start = tt.start
+			}
+
+			clock := gtime.NewManualClock(start)
+			provider := &testProvider{lag: tt.lag}
+			cache := NewCache(provider, WithClock(clock))
+
+			if err := tt.fetch(cache); err != nil {
+				t.Fatalf("fetch error = %v", err)
+			}
+
+			clock.Advance(tt.advance)
+
+			if err := tt.fetch(cache); err != nil {
+				t.Fatalf("fetch error = %v", err)
+			}
+
+			if got := provider.Calls(); got != tt.wantCalls {
+				t.Errorf("provider calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCache_Copies(t *testing.T) {
+	cache := NewCache(&testProvider{})
+
+	first, _ := cache.Latest(gexc.LatestParams{Base: "EUR", Symbols: []string{"TRY"}})
+	first.Rates["TRY"] = 0
+
+	second, _ := cache.Latest(gexc.LatestParams{Base: "EUR", Symbols: []string{"try"}})
+	if second.Rates["TRY"] != 9 || cache.Len() != 1 {
+		t.Errorf("Latest() got = %v, cached responses = %v", second.Rates, cache.Len())
+	}
+}
+
+func TestCache_CalendarFailure(t *testing.T) {
+	never := gtime.CalendarFunc(func(t time.Time) bool { return false })
+	provider := &testProvider{}
+	cache := NewCache(provider, WithCalendar(never))
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Latest(gexc.LatestParams{Base: "EUR"}); err != nil {
+			t.Fatalf("Latest() error = %v", err)
+		}
+	}
+
+	if cache.Len() != 0 || provider.Calls() != 2 {
+		t.Errorf("cached responses = %v, provider calls = %v, want no caching", cache.Len(), provider.Calls())
+	}
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	date := func(d int) gexc.SingleDateParams {
+		return gexc.SingleDateParams{Date: gtime.NewGexc(time.Date(2020, 12, d, 0, 0, 0, 0, time.UTC)), Base: "EUR"}
+	}
+
+	t.Run("should remove the least recently used responses", func(t *testing.T) {
+		clock := gtime.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC))
+		provider := &testProvider{}
+		cache := NewCache(provider, WithClock(clock), WithMaxEntries(2))
+
+		_, _ = cache.SingleDate(date(22))
+		_, _ = cache.SingleDate(date(23))
+		_, _ = cache.SingleDate(date(22))
+		_, _ = cache.SingleDate(date(24))
+
+		if cache.Len() != 2 || provider.Calls() != 3 {
+			t.Fatalf("cached responses = %v, provider calls = %v", cache.Len(), provider.Calls())
+		}
+
+		//2020-12-23 is removed, 2020-12-22 is kept since it is used again
+		_, _ = cache.SingleDate(date(22))
+		_, _ = cache.SingleDate(date(23))
+		if provider.Calls() != 4 {
+			t.Errorf("provider calls = %v, want 4", provider.Calls())
+		}
+	})
+
+	t.Run("should remove the expired responses first", func(t *testing.T) {
+		clock := gtime.NewManualClock(time.Date(2020, 12, 29, 12, 0, 0, 0, time.UTC))
+		provider := &testProvider{}
+		cache := NewCache(provider, WithClock(clock), WithMaxEntries(2))
+
+		_, _ = cache.SingleDate(date(22))
+		_, _ = cache.Latest(gexc.LatestParams{Base: "EUR"})
+
+		//latest rates expire at the publication
+		clock.Advance(4 * time.Hour)
+		_, _ = cache.SingleDate(date(23))
+
+		_, _ = cache.SingleDate(date(22))
+		if cache.Len() != 2 || provider.Calls() != 3 {
+			t.Errorf("cached responses = %v, provider calls = %v", cache.Len(), provider.Calls())
+		}
+	})
+}
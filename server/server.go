@@ -0,0 +1,197 @@
+//Package server serves rates over HTTP in the API of exchangeratesapi.io,
+//so gexc can act as an internal mirror for the services in other languages.
+//
+//	provider := server.NewCache(gexc.New().Provider())
+//	log.Fatal(http.ListenAndServe(":8080", server.New(provider)))
+//
+//The endpoints are /latest, /{date} and /history with base and symbols
+//query parameters. Symbols may be repeated or comma separated. Responses
+//have the JSON shape that response.SingleDate and response.History decode.
+//Errors are returned as {"error": "..."} with status 400 for invalid
+//requests and 502 for the failures of the provider.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//DefaultBase is the base currency of the requests without base parameter
+const DefaultBase = "EUR"
+
+//Server is a http.Handler that serves the rates of a provider
+type Server struct {
+	provider gexc.Provider
+}
+
+//New creates a server of the provider. Wrap the provider
+//by NewCache to avoid fetching the same rates again.
+func New(provider gexc.Provider) *Server {
+	return &Server{provider: provider}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+		return
+	}
+
+	query := r.URL.Query()
+	base := strings.ToUpper(query.Get("base"))
+	if base == "" {
+		base = DefaultBase
+	}
+
+	symbols := parseSymbols(query)
+	if err := validateCurrencies(base, symbols); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch path := strings.Trim(r.URL.Path, "/"); path {
+	case "latest":
+		s.latest(w, base, symbols)
+	case "history":
+		s.history(w, base, symbols, query)
+	default:
+		s.singleDate(w, base, symbols, path)
+	}
+}
+
+func (s *Server) latest(w http.ResponseWriter, base string, symbols []string) {
+	resp, err := s.provider.Latest(gexc.LatestParams{Base: base, Symbols: symbols})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, singleDateBody{Base: resp.Base, Date: resp.Date.String(), Rates: resp.Rates})
+}
+
+func (s *Server) singleDate(w http.ResponseWriter, base string, symbols []string, path string) {
+	date, err := parseDate(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := s.provider.SingleDate(gexc.SingleDateParams{Date: gtime.NewGexc(date), Base: base, Symbols: symbols})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, singleDateBody{Base: resp.Base, Date: resp.Date.String(), Rates: resp.Rates})
+}
+
+func (s *Server) history(w http.ResponseWriter, base string, symbols []string, query url.Values) {
+	startAt, err := parseDate(query.Get("start_at"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	endAt, err := parseDate(query.Get("end_at"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := s.provider.History(gexc.HistoryParams{
+		StartAt: gtime.NewGexc(startAt),
+		EndAt:   gtime.NewGexc(endAt),
+		Base:    base,
+		Symbols: symbols,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, historyBody{
+		Base:    resp.Base,
+		StartAt: resp.StartAt.String(),
+		EndAt:   resp.EndAt.String(),
+		Rates:   resp.Rates,
+	})
+}
+
+type singleDateBody struct {
+	Base  string         `json:"base"`
+	Date  string         `json:"date"`
+	Rates types.RateItem `json:"rates"`
+}
+
+type historyBody struct {
+	Base    string             `json:"base"`
+	StartAt string             `json:"start_at"`
+	EndAt   string             `json:"end_at"`
+	Rates   types.TimeRateItem `json:"rates"`
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+//parseSymbols accepts both repeated and comma separated symbols
+func parseSymbols(query url.Values) []string {
+	var symbols []string
+	for _, value := range query["symbols"] {
+		for _, symbol := range strings.Split(value, ",") {
+			if symbol = strings.TrimSpace(symbol); symbol != "" {
+				symbols = append(symbols, strings.ToUpper(symbol))
+			}
+		}
+	}
+
+	return symbols
+}
+
+//validateCurrencies rejects the unknown currencies before the
+//provider is called, so its failures are not mistaken for them
+func validateCurrencies(base string, symbols []string) error {
+	if _, ok := gexc.CurrencyByCode(base); !ok {
+		return fmt.Errorf("base '%v' is not supported", base)
+	}
+
+	var invalid []string
+	for _, symbol := range symbols {
+		if _, ok := gexc.CurrencyByCode(symbol); !ok {
+			invalid = append(invalid, symbol)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("symbols '%v' are invalid", strings.Join(invalid, ","))
+	}
+
+	return nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	t, err := time.Parse(gtime.GexcLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time data '%v' does not match format '%%Y-%%m-%%d'", value)
+	}
+
+	return t, nil
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorBody{Error: err.Error()})
+}
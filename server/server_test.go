@@ -0,0 +1,167 @@
+package server
+
+import (
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//testProvider serves the same rates for every date and counts the calls
+type testProvider struct {
+	//lag makes the provider serve the rates of the days before the requested ones
+	lag int
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *testProvider) call(base string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	if base != "EUR" {
+		return errors.New("base " + base + " is not supported")
+	}
+
+	return nil
+}
+
+func (p *testProvider) Latest(params gexc.LatestParams) (*response.SingleDate, error) {
+	if err := p.call(params.Base); err != nil {
+		return nil, err
+	}
+
+	date := gtime.NewGexc(time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC))
+	return &response.SingleDate{Base: params.Base, Date: date, Rates: types.RateItem{"TRY": 9}}, nil
+}
+
+func (p *testProvider) SingleDate(params gexc.SingleDateParams) (*response.SingleDate, error) {
+	if err := p.call(params.Base); err != nil {
+		return nil, err
+	}
+
+	date := gtime.NewGexc(params.Date.AddDate(0, 0, -p.lag))
+	return &response.SingleDate{Base: params.Base, Date: date, Rates: types.RateItem{"TRY": 8}}, nil
+}
+
+func (p *testProvider) History(params gexc.HistoryParams) (*response.History, error) {
+	if err := p.call(params.Base); err != nil {
+		return nil, err
+	}
+
+	return &response.History{
+		Base:    params.Base,
+		StartAt: params.StartAt,
+		EndAt:   params.EndAt,
+		Rates: types.TimeRateItem{
+			params.StartAt.String(): {"TRY": 8},
+			gtime.NewGexc(params.EndAt.AddDate(0, 0, -p.lag)).String(): {"TRY": 8},
+		},
+	}, nil
+}
+
+func (p *testProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.calls
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "should serve latest rates",
+			target:     "/latest?symbols=try",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"base":"EUR","date":"2020-12-29","rates":{"TRY":9}}`,
+		},
+		{
+			name:       "should serve rates of a date",
+			target:     "/2020-12-24?base=EUR&symbols=TRY,USD",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"base":"EUR","date":"2020-12-24","rates":{"TRY":8}}`,
+		},
+		{
+			name:       "should serve history",
+			target:     "/history?start_at=2020-12-01&end_at=2020-12-24",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"base":"EUR","start_at":"2020-12-01","end_at":"2020-12-24","rates":{"2020-12-01":{"TRY":8},"2020-12-24":{"TRY":8}}}`,
+		},
+		{
+			name:       "should return errors of the provider as bad gateway",
+			target:     "/latest?base=USD",
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `{"error":"base USD is not supported"}`,
+		},
+		{
+			name:       "should return an error for unknown bases",
+			target:     "/latest?base=XYZ",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"base 'XYZ' is not supported"}`,
+		},
+		{
+			name:       "should return an error for unknown symbols",
+			target:     "/2020-12-24?symbols=TRY,ABC,XYZ",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"symbols 'ABC,XYZ' are invalid"}`,
+		},
+		{
+			name:       "should return an error for invalid dates",
+			target:     "/history?start_at=2020-12-01",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"time data '' does not match format '%Y-%m-%d'"}`,
+		},
+		{
+			name:       "should not allow other methods",
+			method:     http.MethodPost,
+			target:     "/latest",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"method POST is not allowed"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			w := httptest.NewRecorder()
+			New(&testProvider{}).ServeHTTP(w, httptest.NewRequest(method, tt.target, nil))
+
+			body, _ := ioutil.ReadAll(w.Body)
+			if w.Code != tt.wantStatus || strings.TrimSpace(string(body)) != tt.wantBody {
+				t.Errorf("ServeHTTP() = %v %s, want %v %v", w.Code, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Client(t *testing.T) {
+	server := httptest.NewServer(New(&testProvider{}))
+	defer server.Close()
+
+	fx := gexc.New(gexc.WithEndpoint(server.URL))
+
+	conversion, err := fx.ConvertAt(2, "EUR", "TRY", time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC))
+	if err != nil || conversion.Result != 16 || conversion.Date.String() != "2020-12-24" {
+		t.Errorf("ConvertAt() got = %v, error = %v", conversion, err)
+	}
+}
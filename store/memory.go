@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Memory is a Store that keeps the rates in memory.
+//It is safe for concurrent use.
+type Memory struct {
+	mu    sync.RWMutex
+	rates map[memoryKey]Rate
+}
+
+type memoryKey struct {
+	source   string
+	base     string
+	date     time.Time
+	currency string
+}
+
+//NewMemory creates an empty memory store
+func NewMemory() *Memory {
+	return &Memory{rates: make(map[memoryKey]Rate)}
+}
+
+func (m *Memory) Save(ctx context.Context, rates []Rate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rate := range rates {
+		rate.Source = sourceOrDefault(rate.Source)
+		rate.Date = truncateDay(rate.Date)
+
+		m.rates[memoryKey{source: rate.Source, base: rate.Base, date: rate.Date, currency: rate.Currency}] = rate
+	}
+
+	return nil
+}
+
+func (m *Memory) LastDate(ctx context.Context, source, base string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	source = sourceOrDefault(source)
+
+	var last time.Time
+	for key := range m.rates {
+		if key.source == source && key.base == base && key.date.After(last) {
+			last = key.date
+		}
+	}
+
+	if last.IsZero() {
+		return time.Time{}, ErrNotFound
+	}
+
+	return last, nil
+}
+
+func (m *Memory) Rates(ctx context.Context, query Query) ([]Rate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	source := sourceOrDefault(query.Source)
+	currencies := make(map[string]bool, len(query.Currencies))
+	for _, code := range query.Currencies {
+		currencies[strings.ToUpper(code)] = true
+	}
+
+	var rates []Rate
+	for key, rate := range m.rates {
+		if key.source != source || key.base != query.Base ||
+			(!query.From.IsZero() && key.date.Before(truncateDay(query.From))) ||
+			(!query.Until.IsZero() && key.date.After(truncateDay(query.Until))) ||
+			(len(currencies) > 0 && !currencies[key.currency]) {
+			continue
+		}
+
+		rates = append(rates, rate)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		if !rates[i].Date.Equal(rates[j].Date) {
+			return rates[i].Date.Before(rates[j].Date)
+		}
+
+		return rates[i].Currency < rates[j].Currency
+	})
+
+	if query.LatestOnly && len(rates) > 0 {
+		last := rates[len(rates)-1].Date
+		first := sort.Search(len(rates), func(i int) bool {
+			return !rates[i].Date.Before(last)
+		})
+
+		rates = rates[first:]
+	}
+
+	return rates, nil
+}
+
+var _ Store = (*Memory)(nil)
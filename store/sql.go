@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//dateLayout is the layout of the date column. Dates are stored as text,
+//so they are compared and ordered the same way in all databases.
+const dateLayout = "2006-01-02"
+
+//Placeholder is the style of the query parameters of a database
+type Placeholder int
+
+const (
+	//Question is the ? style of SQLite and MySQL
+	Question Placeholder = iota
+	//Dollar is the $1 style of PostgreSQL
+	Dollar
+)
+
+//SQL is a Store in a database/sql database.
+//Its table is created by Migrate.
+type SQL struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+//SQLOption customizes SQL while it is created by NewSQL
+type SQLOption func(s *SQL)
+
+//WithTable sets the name of the table, default is gexc_rates
+func WithTable(name string) SQLOption {
+	return func(s *SQL) {
+		s.table = name
+	}
+}
+
+//WithPlaceholder sets the style of the query parameters, default is Question
+func WithPlaceholder(placeholder Placeholder) SQLOption {
+	return func(s *SQL) {
+		s.placeholder = placeholder
+	}
+}
+
+//NewSQL creates a store in the database
+func NewSQL(db *sql.DB, options ...SQLOption) *SQL {
+	s := &SQL{db: db, table: "gexc_rates"}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+//Migrate creates the table of the rates if it does not exist
+func (s *SQL) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.table+` (
+	source VARCHAR(64) NOT NULL,
+	base CHAR(3) NOT NULL,
+	date CHAR(10) NOT NULL,
+	currency CHAR(3) NOT NULL,
+	rate DOUBLE PRECISION NOT NULL,
+	fetched_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (source, base, date, currency)
+)`)
+	return err
+}
+
+//Save replaces the stored rates in a transaction. Rows are deleted and
+//inserted instead of upserted, since upsert syntax differs by database.
+func (s *SQL) Save(ctx context.Context, rates []Rate) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	deleteStmt, err := tx.PrepareContext(ctx, s.rebind(
+		`DELETE FROM `+s.table+` WHERE source = ? AND base = ? AND date = ? AND currency = ?`))
+	if err != nil {
+		return err
+	}
+
+	defer deleteStmt.Close()
+
+	insertStmt, err := tx.PrepareContext(ctx, s.rebind(
+		`INSERT INTO `+s.table+` (source, base, date, currency, rate, fetched_at) VALUES (?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return err
+	}
+
+	defer insertStmt.Close()
+
+	for _, rate := range rates {
+		source := sourceOrDefault(rate.Source)
+		date := rate.Date.Format(dateLayout)
+
+		if _, err := deleteStmt.ExecContext(ctx, source, rate.Base, date, rate.Currency); err != nil {
+			return fmt.Errorf("deleting %v of %v at %v: %w", rate.Currency, rate.Base, date, err)
+		}
+
+		if _, err := insertStmt.ExecContext(ctx, source, rate.Base, date, rate.Currency, rate.Rate, rate.FetchedAt.UTC()); err != nil {
+			return fmt.Errorf("inserting %v of %v at %v: %w", rate.Currency, rate.Base, date, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQL) LastDate(ctx context.Context, source, base string) (time.Time, error) {
+	var last sql.NullString
+
+	query := s.rebind(`SELECT MAX(date) FROM ` + s.table + ` WHERE source = ? AND base = ?`)
+	if err := s.db.QueryRowContext(ctx, query, sourceOrDefault(source), base).Scan(&last); err != nil {
+		return time.Time{}, err
+	}
+
+	if !last.Valid {
+		return time.Time{}, ErrNotFound
+	}
+
+	return time.Parse(dateLayout, last.String)
+}
+
+func (s *SQL) Rates(ctx context.Context, query Query) ([]Rate, error) {
+	where, args := s.where(query)
+	if query.LatestOnly {
+		where += ` AND date = (SELECT MAX(date) FROM ` + s.table + ` WHERE ` + where + `)`
+		args = append(args, args...)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(
+		`SELECT date, base, currency, rate, source, fetched_at FROM `+s.table+
+			` WHERE `+where+` ORDER BY date, currency`), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var rates []Rate
+	for rows.Next() {
+		var rate Rate
+		var date string
+
+		if err := rows.Scan(&date, &rate.Base, &rate.Currency, &rate.Rate, &rate.Source, &rate.FetchedAt); err != nil {
+			return nil, err
+		}
+
+		if rate.Date, err = time.Parse(dateLayout, date); err != nil {
+			return nil, fmt.Errorf("invalid stored date %q: %w", date, err)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+//where returns the conditions of the query with ? placeholders
+func (s *SQL) where(query Query) (string, []interface{}) {
+	conditions := []string{"source = ?", "base = ?"}
+	args := []interface{}{sourceOrDefault(query.Source), query.Base}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, query.From.Format(dateLayout))
+	}
+
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, query.Until.Format(dateLayout))
+	}
+
+	if len(query.Currencies) > 0 {
+		placeholders := make([]string, len(query.Currencies))
+		for i, code := range query.Currencies {
+			placeholders[i] = "?"
+			args = append(args, strings.ToUpper(code))
+		}
+
+		conditions = append(conditions, "currency IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+//rebind converts ? placeholders to the style of the database
+func (s *SQL) rebind(query string) string {
+	if s.placeholder != Dollar {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+var _ Store = (*SQL)(nil)
@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//fakeDB is a database/sql driver that records the statements
+//and returns the rows that are set for the queries
+type fakeDB struct {
+	mu        sync.Mutex
+	execs     []fakeExec
+	rows      [][]driver.Value
+	columns   []string
+	commits   int
+	rollbacks int
+	failExec  error
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (db *fakeDB) Connect(ctx context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.execs = append(s.db.execs, fakeExec{query: s.query, args: args})
+	if s.db.failExec != nil {
+		return nil, s.db.failExec
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.execs = append(s.db.execs, fakeExec{query: s.query, args: args})
+	return &fakeRows{columns: s.db.columns, rows: s.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQL_Save(t *testing.T) {
+	fetchedAt := time.Date(2020, 12, 29, 18, 0, 0, 0, time.UTC)
+	rates := []Rate{{Date: date(2020, 12, 24), Base: "EUR", Currency: "TRY", Rate: 9.1, FetchedAt: fetchedAt}}
+
+	t.Run("should replace the rates in a transaction", func(t *testing.T) {
+		db := &fakeDB{}
+		s := NewSQL(sql.OpenDB(db), WithPlaceholder(Dollar), WithTable("rates"))
+
+		if err := s.Save(context.Background(), rates); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []fakeExec{
+			{
+				query: "DELETE FROM rates WHERE source = $1 AND base = $2 AND date = $3 AND currency = $4",
+				args:  []driver.Value{DefaultSource, "EUR", "2020-12-24", "TRY"},
+			},
+			{
+				query: "INSERT INTO rates (source, base, date, currency, rate, fetched_at) VALUES ($1, $2, $3, $4, $5, $6)",
+				args:  []driver.Value{DefaultSource, "EUR", "2020-12-24", "TRY", 9.1, fetchedAt},
+			},
+		}
+
+		if !reflect.DeepEqual(db.execs, want) || db.commits != 1 {
+			t.Errorf("Save() execs = %v, commits = %v, want %v", db.execs, db.commits, want)
+		}
+	})
+
+	t.Run("should roll back on failures", func(t *testing.T) {
+		db := &fakeDB{failExec: errors.New("disk is full")}
+		s := NewSQL(sql.OpenDB(db))
+
+		if err := s.Save(context.Background(), rates); err == nil || !strings.Contains(err.Error(), "disk is full") {
+			t.Errorf("Save() error = %v", err)
+		}
+
+		if db.commits != 0 || db.rollbacks != 1 {
+			t.Errorf("Save() commits = %v, rollbacks = %v", db.commits, db.rollbacks)
+		}
+	})
+}
+
+func TestSQL_LastDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   driver.Value
+		want    time.Time
+		wantErr error
+	}{
+		{name: "should parse the last date", value: "2020-12-24", want: date(2020, 12, 24)},
+		{name: "should return ErrNotFound for empty tables", value: nil, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{columns: []string{"max"}, rows: [][]driver.Value{{tt.value}}}
+			got, err := NewSQL(sql.OpenDB(db)).LastDate(context.Background(), "", "EUR")
+
+			if !errors.Is(err, tt.wantErr) || !got.Equal(tt.want) {
+				t.Errorf("LastDate() got = %v, error = %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+
+			want := fakeExec{
+				query: "SELECT MAX(date) FROM gexc_rates WHERE source = ? AND base = ?",
+				args:  []driver.Value{DefaultSource, "EUR"},
+			}
+
+			if !reflect.DeepEqual(db.execs, []fakeExec{want}) {
+				t.Errorf("LastDate() execs = %v, want %v", db.execs, want)
+			}
+		})
+	}
+}
+
+func TestSQL_Rates(t *testing.T) {
+	fetchedAt := time.Date(2020, 12, 29, 18, 0, 0, 0, time.UTC)
+	db := &fakeDB{
+		columns: []string{"date", "base", "currency", "rate", "source", "fetched_at"},
+		rows:    [][]driver.Value{{"2020-12-24", "EUR", "TRY", 9.1, "ecb", fetchedAt}},
+	}
+
+	got, err := NewSQL(sql.OpenDB(db)).Rates(context.Background(), Query{
+		Source:     "ecb",
+		Base:       "EUR",
+		Until:      date(2020, 12, 27),
+		Currencies: []string{"try"},
+		LatestOnly: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Rate{{Date: date(2020, 12, 24), Base: "EUR", Currency: "TRY", Rate: 9.1, Source: "ecb", FetchedAt: fetchedAt}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rates() got = %v, want %v", got, want)
+	}
+
+	wantExec := fakeExec{
+		query: "SELECT date, base, currency, rate, source, fetched_at FROM gexc_rates" +
+			" WHERE source = ? AND base = ? AND date <= ? AND currency IN (?)" +
+			" AND date = (SELECT MAX(date) FROM gexc_rates WHERE source = ? AND base = ? AND date <= ? AND currency IN (?))" +
+			" ORDER BY date, currency",
+		args: []driver.Value{"ecb", "EUR", "2020-12-27", "TRY", "ecb", "EUR", "2020-12-27", "TRY"},
+	}
+
+	if !reflect.DeepEqual(db.execs, []fakeExec{wantExec}) {
+		t.Errorf("Rates() execs = %v, want %v", db.execs, wantExec)
+	}
+}
+
+func TestSQL_Migrate(t *testing.T) {
+	db := &fakeDB{}
+	if err := NewSQL(sql.OpenDB(db)).Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.execs) != 1 || !strings.HasPrefix(db.execs[0].query, "CREATE TABLE IF NOT EXISTS gexc_rates (") ||
+		!strings.Contains(db.execs[0].query, "PRIMARY KEY (source, base, date, currency)") {
+		t.Errorf("Migrate() execs = %v", db.execs)
+	}
+}
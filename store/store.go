@@ -0,0 +1,74 @@
+//Package store keeps an archive of the rates, so the history is not
+//fetched again. Sync fetches only the days after the last stored date.
+//
+//	archive := store.NewSQL(db)
+//	if err := archive.Migrate(ctx); err != nil {
+//		...
+//	}
+//
+//	result, err := store.Sync(ctx, archive, fx, store.SyncOptions{
+//		Base: "EUR",
+//		From: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+//	})
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+//DefaultSource is the source of the rates that are saved without one
+const DefaultSource = "gexc"
+
+//ErrNotFound is returned if the store has no rates for the query
+var ErrNotFound = errors.New("rates not found")
+
+//Rate is a stored rate of a currency against the base at a date
+type Rate struct {
+	//Date is the publication day, in UTC
+	Date     time.Time
+	Base     string
+	Currency string
+	Rate     float64
+	//Source is the provider that the rate is fetched from
+	Source    string
+	FetchedAt time.Time
+}
+
+//Query selects the stored rates. Zero From and Until are unbounded
+//and empty Currencies means all currencies.
+type Query struct {
+	Source     string
+	Base       string
+	From       time.Time
+	Until      time.Time
+	Currencies []string
+	//LatestOnly selects the rates of the last date in the range only
+	LatestOnly bool
+}
+
+//Store is an archive of the rates.
+//Rates are identified by source, base, date and currency.
+type Store interface {
+	//Save inserts the rates or replaces the stored ones, so
+	//saving the same rates again does not change the store
+	Save(ctx context.Context, rates []Rate) error
+	//LastDate returns the last date that has rates of the source
+	//and base, or ErrNotFound if there is none
+	LastDate(ctx context.Context, source, base string) (time.Time, error)
+	//Rates returns the rates of the query ordered by date and currency
+	Rates(ctx context.Context, query Query) ([]Rate, error)
+}
+
+func sourceOrDefault(source string) string {
+	if source == "" {
+		return DefaultSource
+	}
+
+	return source
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/fufuceng/gexc"
+	"github.com/fufuceng/gexc/gexctest"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	if _, err := m.LastDate(ctx, "", "EUR"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LastDate() error = %v, want %v", err, ErrNotFound)
+	}
+
+	rates := []Rate{
+		{Date: date(2020, 12, 24), Base: "EUR", Currency: "USD", Rate: 1.2},
+		{Date: date(2020, 12, 24), Base: "EUR", Currency: "TRY", Rate: 9.1},
+		{Date: date(2020, 12, 28), Base: "EUR", Currency: "TRY", Rate: 9},
+		{Date: date(2020, 12, 29), Base: "EUR", Currency: "TRY", Rate: 8.9, Source: "other"},
+	}
+
+	//saving twice does not duplicate the rates
+	for i := 0; i < 2; i++ {
+		if err := m.Save(ctx, rates); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	last, err := m.LastDate(ctx, "", "EUR")
+	if err != nil || !last.Equal(date(2020, 12, 28)) {
+		t.Errorf("LastDate() got = %v, error = %v", last, err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []float64
+	}{
+		{name: "should order by date and currency", query: Query{Base: "EUR"}, want: []float64{9.1, 1.2, 9}},
+		{name: "should filter currencies", query: Query{Base: "EUR", Currencies: []string{"usd"}}, want: []float64{1.2}},
+		{name: "should filter dates", query: Query{Base: "EUR", From: date(2020, 12, 25)}, want: []float64{9}},
+		{name: "should select the last date", query: Query{Base: "EUR", Until: date(2020, 12, 27), LatestOnly: true}, want: []float64{9.1, 1.2}},
+		{name: "should select the source", query: Query{Base: "EUR", Source: "other"}, want: []float64{8.9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Rates(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var values []float64
+			for _, rate := range got {
+				values = append(values, rate.Rate)
+			}
+
+			if !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("Rates() got = %v, want %v", values, tt.want)
+			}
+		})
+	}
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+
+	//rates of 2021-01-06 are published at 15:00 UTC
+	clock := gtime.NewManualClock(time.Date(2021, 1, 6, 18, 0, 0, 0, time.UTC))
+	provider := gexctest.NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": 9, "USD": 1.2}).
+		SetClock(clock)
+
+	fx := gexctest.NewFx(provider, gexc.WithClock(clock), gexc.WithHistoryWindow(5))
+	archive := NewMemory()
+	options := SyncOptions{Base: "EUR", From: date(2020, 12, 21)}
+
+	if _, err := Sync(ctx, archive, fx, SyncOptions{Base: "EUR"}); !errors.Is(err, gexc.ErrInvalidParameter) {
+		t.Errorf("Sync() error = %v for the first sync without from", err)
+	}
+
+	//a failure keeps the rates before it
+	provider.FailAt(date(2020, 12, 30), errors.New("service is down"))
+
+	result, err := Sync(ctx, archive, fx, options)
+	if err == nil || result.Dates != 6 {
+		t.Fatalf("Sync() got = %+v, error = %v, want a failure after 6 dates", result, err)
+	}
+
+	provider.FailAt(date(2020, 12, 30), nil)
+
+	result, err = Sync(ctx, archive, fx, options)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	//30 and 31 December, 4, 5 and 6 January, new year is a holiday
+	want := SyncResult{Start: date(2020, 12, 30), End: date(2021, 1, 6), Dates: 5, Rates: 10}
+	if result != want {
+		t.Errorf("Sync() got = %+v, want %+v", result, want)
+	}
+
+	if result, err = Sync(ctx, archive, fx, options); err != nil || result.Dates != 0 {
+		t.Errorf("Sync() got = %+v, error = %v, want no-op", result, err)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if result, err = Sync(ctx, archive, fx, options); err != nil || result.Dates != 1 || !result.Start.Equal(date(2021, 1, 7)) {
+		t.Errorf("Sync() got = %+v, error = %v, want the new day", result, err)
+	}
+
+	rates, _ := archive.Rates(ctx, Query{Base: "EUR", Currencies: []string{"TRY"}})
+	if len(rates) != 12 || !rates[0].Date.Equal(date(2020, 12, 21)) || !rates[0].FetchedAt.Equal(time.Date(2021, 1, 6, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Rates() got = %v", rates)
+	}
+}
+
+func TestSync_Symbols(t *testing.T) {
+	ctx := context.Background()
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 18, 0, 0, 0, time.UTC))
+	provider := gexctest.NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": 9, "USD": 1.2}).
+		SetClock(clock)
+
+	fx := gexctest.NewFx(provider, gexc.WithClock(clock))
+	archive := NewMemory()
+	from := date(2020, 12, 28)
+
+	if _, err := Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"TRY"}, From: from}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	//USD is missing, so it is synced from the start
+	result, err := Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"USD"}, From: from})
+	want := SyncResult{Start: from, End: date(2020, 12, 29), Dates: 2, Rates: 2}
+	if err != nil || result != want {
+		t.Errorf("Sync() got = %+v, error = %v, want %+v", result, err, want)
+	}
+
+	//both symbols are up to date
+	result, err = Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"TRY", "USD", "EUR"}})
+	if err != nil || result.Dates != 0 {
+		t.Errorf("Sync() got = %+v, error = %v, want no-op", result, err)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if _, err := Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"TRY"}}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	//TRY is synced a day further, so the sync starts from the last date of USD
+	result, err = Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"TRY", "USD"}})
+	want = SyncResult{Start: date(2020, 12, 30), End: date(2020, 12, 30), Dates: 1, Rates: 2}
+	if err != nil || result != want {
+		t.Errorf("Sync() got = %+v, error = %v, want %+v", result, err, want)
+	}
+
+	if _, err := Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"GBP"}}); !errors.Is(err, gexc.ErrInvalidParameter) {
+		t.Errorf("Sync() error = %v for a new symbol without from", err)
+	}
+}
+
+func TestSync_Normalize(t *testing.T) {
+	ctx := context.Background()
+	clock := gtime.NewManualClock(time.Date(2020, 12, 29, 18, 0, 0, 0, time.UTC))
+	provider := gexctest.NewProvider("EUR").
+		SetStatic(types.RateItem{"TRY": 9, "USD": 1.2}).
+		SetClock(clock)
+
+	fx := gexctest.NewFx(provider, gexc.WithClock(clock))
+	archive := NewMemory()
+
+	if _, err := Sync(ctx, archive, fx, SyncOptions{Base: "eur", Symbols: []string{"try"}, From: date(2020, 12, 28)}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	//rates are stored by upper case codes, as the other packages query them
+	last, err := archive.LastDate(ctx, "", "EUR")
+	rates, _ := archive.Rates(ctx, Query{Base: "EUR"})
+	if err != nil || !last.Equal(date(2020, 12, 29)) || len(rates) != 2 || rates[0].Currency != "TRY" {
+		t.Errorf("LastDate() = %v, error = %v, Rates() = %v", last, err, rates)
+	}
+
+	if _, err := Sync(ctx, archive, fx, SyncOptions{Base: "EUR", Symbols: []string{"XYZ"}}); !errors.Is(err, gexc.ErrUnsupportedCurrency) {
+		t.Errorf("Sync() error = %v, want %v", err, gexc.ErrUnsupportedCurrency)
+	}
+}
@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fufuceng/gexc"
+	gtime "github.com/fufuceng/gexc/time"
+	"sort"
+	"time"
+)
+
+//saveBatch is the number of dates that are saved at once while syncing
+const saveBatch = 100
+
+//SyncOptions selects the rates that are synced
+type SyncOptions struct {
+	//Source is saved with the rates, default is DefaultSource
+	Source string
+	Base   string
+	//Symbols are the synced currencies, empty means all
+	Symbols []string
+	//From is the first date that is synced if the store has no rates
+	//of the source and base yet, or no rates of one of the Symbols
+	From time.Time
+	//Until is the last date that is synced, default is the
+	//last published day by the clock of fx
+	Until time.Time
+	//Calendar decides the last published day, default is gtime.TargetCalendar
+	Calendar gtime.Calendar
+}
+
+//SyncResult tells what is synced
+type SyncResult struct {
+	//Start and End are the range that is fetched,
+	//Start is after End if the store was up to date
+	Start time.Time
+	End   time.Time
+	//Dates and Rates are the number of the saved dates and rates
+	Dates int
+	Rates int
+}
+
+//Sync fetches the rates of the days after the last stored date until
+//Until and saves them. With Symbols, the last stored date is the earliest
+//one of the symbols, so a symbol that is added later is synced from From
+//and the rates of the other symbols are saved again. Rates are saved in chronological batches while
+//they are fetched, so a failed sync keeps the rates before the failure
+//and the next sync continues from there. Syncing again is a no-op.
+func Sync(ctx context.Context, store Store, fx *gexc.Fx, options SyncOptions) (SyncResult, error) {
+	source := sourceOrDefault(options.Source)
+
+	//rates are stored and queried by upper case codes
+	base, ok := gexc.CurrencyByCode(options.Base)
+	if !ok {
+		return SyncResult{}, fmt.Errorf("%w: %v", gexc.ErrUnsupportedCurrency, options.Base)
+	}
+
+	symbols := make([]string, 0, len(options.Symbols))
+	for _, code := range options.Symbols {
+		currency, ok := gexc.CurrencyByCode(code)
+		if !ok {
+			return SyncResult{}, fmt.Errorf("%w: %v", gexc.ErrUnsupportedCurrency, code)
+		}
+
+		symbols = append(symbols, currency.Code)
+	}
+
+	until := options.Until
+	if until.IsZero() {
+		published, err := gtime.LatestPublishedDay(options.Calendar, fx.Clock().Now())
+		if err != nil {
+			return SyncResult{}, err
+		}
+
+		until = published
+	}
+
+	start := truncateDay(options.From)
+	last, err := lastDate(ctx, store, source, base.Code, symbols)
+	switch {
+	case err == nil:
+		start = last.AddDate(0, 0, 1)
+	case !errors.Is(err, ErrNotFound):
+		return SyncResult{}, err
+	case options.From.IsZero():
+		return SyncResult{}, fmt.Errorf("%w: from should not be empty for the first sync", gexc.ErrInvalidParameter)
+	}
+
+	result := SyncResult{Start: start, End: truncateDay(until)}
+	if start.After(result.End) {
+		return result, nil
+	}
+
+	//history needs a range of two days at least,
+	//the dates before start are skipped
+	it := fx.BasedOn(base.Code).Against(symbols...).From(start.AddDate(0, 0, -1)).Stream(ctx, result.End)
+	defer it.Close()
+
+	var batch []Rate
+	var dates int
+	save := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := store.Save(ctx, batch); err != nil {
+			return err
+		}
+
+		result.Dates += dates
+		result.Rates += len(batch)
+		batch, dates = nil, 0
+		return nil
+	}
+
+	fetchedAt := fx.Clock().Now()
+	for it.Next() {
+		date := truncateDay(it.Date().Time)
+		if date.Before(start) {
+			continue
+		}
+
+		rates := it.Rates()
+		codes := make([]string, 0, len(rates))
+		for code := range rates {
+			codes = append(codes, code)
+		}
+
+		sort.Strings(codes)
+
+		for _, code := range codes {
+			batch = append(batch, Rate{
+				Date:      date,
+				Base:      base.Code,
+				Currency:  code,
+				Rate:      rates[code],
+				Source:    source,
+				FetchedAt: fetchedAt,
+			})
+		}
+
+		if dates++; dates == saveBatch {
+			if err := save(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	//the rates before the failure are saved, since they are in order
+	if err := save(); err != nil {
+		return result, err
+	}
+
+	return result, it.Err()
+}
+
+//lastDate returns the last stored date of the base, or the earliest
+//one of the symbols. Returns ErrNotFound if a symbol has no rates.
+func lastDate(ctx context.Context, store Store, source, base string, symbols []string) (time.Time, error) {
+	if len(symbols) == 0 {
+		return store.LastDate(ctx, source, base)
+	}
+
+	var last time.Time
+	for _, symbol := range symbols {
+		//rates of the base against itself are not stored
+		if symbol == base {
+			continue
+		}
+
+		rates, err := store.Rates(ctx, Query{
+			Source:     source,
+			Base:       base,
+			Currencies: []string{symbol},
+			LatestOnly: true,
+		})
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if len(rates) == 0 {
+			return time.Time{}, fmt.Errorf("%w: %v", ErrNotFound, symbol)
+		}
+
+		if date := rates[0].Date; last.IsZero() || date.Before(last) {
+			last = date
+		}
+	}
+
+	if last.IsZero() {
+		return store.LastDate(ctx, source, base)
+	}
+
+	return last, nil
+}
@@ -0,0 +1,223 @@
+package gexc
+
+import (
+	"context"
+	"fmt"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"sort"
+	"time"
+)
+
+//HistoryIterator yields the rates of a history range in chronological order.
+//Windows of the range are fetched while the iterator is consumed, so the
+//whole range is never kept in memory. It should be closed after use.
+//
+//	it := fx.BasedOn("EUR").Against("TRY").From(from).Stream(ctx, until)
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Date(), it.Rates())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type HistoryIterator struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	items   chan historyItem
+	current historyItem
+	err     error
+}
+
+type historyItem struct {
+	date  gtime.Gexc
+	rates types.RateItem
+	err   error
+}
+
+type windowResult struct {
+	window  Window
+	history *response.History
+	err     error
+}
+
+//Stream is the alternative last step of the history.
+//It validates all parameters and returns an iterator over the range
+//instead of fetching it at once. Cancelling the context stops the iteration.
+func (f *fxHistoryUntilWrapper) Stream(ctx context.Context, t time.Time) *HistoryIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &HistoryIterator{
+		ctx:    ctx,
+		cancel: cancel,
+		items:  make(chan historyItem),
+	}
+
+	params, err := f.params(t)
+	if err != nil {
+		it.err = err
+		close(it.items)
+		return it
+	}
+
+	go it.produce(f.base, params)
+	return it
+}
+
+//Next advances the iterator to the next date.
+//It returns false when the range is finished or an error occurs.
+func (it *HistoryIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.setErr(err)
+		return false
+	}
+
+	select {
+	case item, ok := <-it.items:
+		if !ok {
+			return false
+		}
+
+		if item.err != nil {
+			it.setErr(item.err)
+			return false
+		}
+
+		it.current = item
+		return true
+	case <-it.ctx.Done():
+		it.setErr(it.ctx.Err())
+		return false
+	}
+}
+
+//Date returns the date of the current rates
+func (it *HistoryIterator) Date() gtime.Gexc {
+	return it.current.date
+}
+
+//Rates returns the rates of the current date
+func (it *HistoryIterator) Rates() types.RateItem {
+	return it.current.rates
+}
+
+//Err returns the error that stopped the iteration, if any.
+//Failed windows are reported as *HistoryError.
+func (it *HistoryIterator) Err() error {
+	return it.err
+}
+
+//Close stops fetching the remaining windows
+func (it *HistoryIterator) Close() {
+	it.cancel()
+}
+
+func (it *HistoryIterator) setErr(err error) {
+	if it.err == nil {
+		it.err = err
+	}
+}
+
+//produce fetches the windows concurrently and sends
+//their rates to the iterator in chronological order
+func (it *HistoryIterator) produce(f *Fx, params openex.HistoryParams) {
+	defer close(it.items)
+
+	//stops the dispatcher if the producer returns early
+	ctx, cancel := context.WithCancel(it.ctx)
+	defer cancel()
+
+	windows := splitWindows(params.StartAt.Time, params.EndAt.Time, f.window())
+	results := make(chan chan windowResult, f.workers())
+	sem := make(chan struct{}, f.workers())
+
+	go func() {
+		defer close(results)
+
+		for _, window := range windows {
+			result := make(chan windowResult, 1)
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+
+			//results are buffered ahead of the consumer,
+			//the semaphore bounds the requests in flight
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(window Window) {
+				defer func() { <-sem }()
+
+				history, err := f.openexClient.History(windowParams(params, window))
+				result <- windowResult{window: window, history: history, err: err}
+			}(window)
+		}
+	}()
+
+	for result := range results {
+		var r windowResult
+		select {
+		case r = <-result:
+		case <-it.ctx.Done():
+			return
+		}
+
+		if r.err != nil {
+			it.fail(&HistoryError{
+				Failed:  []WindowError{{Window: r.window, Err: r.err}},
+				Windows: len(windows),
+			})
+			return
+		}
+
+		items, err := sortedItems(r.history)
+		if err != nil {
+			it.fail(fmt.Errorf("%w: %v", ErrClientFailed, err))
+			return
+		}
+
+		for _, item := range items {
+			select {
+			case it.items <- item:
+			case <-it.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+//fail reports the error to the consumer through the iterator
+func (it *HistoryIterator) fail(err error) {
+	select {
+	case it.items <- historyItem{err: err}:
+	case <-it.ctx.Done():
+	}
+}
+
+func sortedItems(history *response.History) ([]historyItem, error) {
+	items := make([]historyItem, 0, len(history.Rates))
+	for date, rates := range history.Rates {
+		t, err := time.Parse(gtime.GexcLayout, date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid history date %q: %v", date, err)
+		}
+
+		items = append(items, historyItem{date: gtime.NewGexc(t), rates: rates})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].date.Before(items[j].date.Time)
+	})
+
+	return items, nil
+}
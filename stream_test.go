@@ -0,0 +1,105 @@
+package gexc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFx_HistoryStream(t *testing.T) {
+	from := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2020, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		from      time.Time
+		failAt    map[string]bool
+		stopAfter int
+		wantDays  []float64
+		wantErr   error
+	}{
+		{
+			name:     "should yield all dates in chronological order",
+			from:     from,
+			wantDays: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name:     "should yield dates until the failed window and report it",
+			from:     from,
+			failAt:   map[string]bool{"2020-12-05": true},
+			wantDays: []float64{1, 2, 3, 4},
+			wantErr:  ErrClientFailed,
+		},
+		{
+			name:      "should stop when the context is cancelled",
+			from:      from,
+			stopAfter: 3,
+			wantDays:  []float64{1, 2, 3},
+			wantErr:   context.Canceled,
+		},
+		{
+			name:    "should report validation errors",
+			from:    time.Time{},
+			wantErr: ErrInvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := &windowTestClient{failAt: tt.failAt}
+			f := newFxWithClient(client, WithHistoryWindow(4), WithConcurrency(2))
+
+			it := f.BasedOn("EUR").Against("TRY").From(tt.from).Stream(ctx, until)
+			defer it.Close()
+
+			var days []float64
+			for it.Next() {
+				days = append(days, it.Rates()["TRY"])
+				if float64(it.Date().Day()) != it.Rates()["TRY"] {
+					t.Errorf("Stream() date = %v, rates = %v", it.Date(), it.Rates())
+				}
+
+				if len(days) == tt.stopAfter {
+					cancel()
+				}
+			}
+
+			if !reflect.DeepEqual(days, tt.wantDays) {
+				t.Errorf("Stream() days = %v, want %v", days, tt.wantDays)
+			}
+
+			if !errors.Is(it.Err(), tt.wantErr) || (tt.wantErr == nil) != (it.Err() == nil) {
+				t.Errorf("Stream() error = %v, want %v", it.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFx_HistoryStreamConcurrency(t *testing.T) {
+	client := &windowTestClient{delay: 10 * time.Millisecond}
+	f := newFxWithClient(client, WithHistoryWindow(1), WithConcurrency(2))
+
+	from := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	it := f.BasedOn("EUR").Against("TRY").From(from).Stream(context.Background(), from.AddDate(0, 0, 9))
+	defer it.Close()
+
+	var days int
+	for it.Next() {
+		//a slow consumer lets the windows finish before they are read
+		time.Sleep(5 * time.Millisecond)
+		days++
+	}
+
+	if it.Err() != nil || days != 10 {
+		t.Fatalf("Stream() days = %v, error = %v", days, it.Err())
+	}
+
+	if client.maxActive > 2 {
+		t.Errorf("Stream() sent %v requests at the same time, want at most 2", client.maxActive)
+	}
+}
@@ -0,0 +1,216 @@
+package time
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	//publication times are calculated in Europe/Berlin
+	//which may not exist on the host
+	_ "time/tzdata"
+)
+
+//PublicationHour is the hour of the day, in Central European Time,
+//that the European Central Bank publishes the reference rates
+const PublicationHour = 16
+
+//maxSearchDays bounds the search of the publication days,
+//so a calendar that never publishes does not hang the search
+const maxSearchDays = 366
+
+//ErrNoPublicationDay is returned if a calendar has no
+//publication day within maxSearchDays of the given time
+var ErrNoPublicationDay = errors.New("no publication day within a year")
+
+var publicationLocation = loadPublicationLocation()
+
+func loadPublicationLocation() *time.Location {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		return time.FixedZone("CET", 60*60)
+	}
+
+	return location
+}
+
+//Calendar tells on which days the rates are published
+type Calendar interface {
+	IsPublicationDay(t time.Time) bool
+}
+
+//CalendarFunc is an adapter to use ordinary functions as Calendar
+type CalendarFunc func(t time.Time) bool
+
+//IsPublicationDay calls f(t)
+func (f CalendarFunc) IsPublicationDay(t time.Time) bool {
+	return f(t)
+}
+
+//WeekdayCalendar publishes rates on every day except weekends
+type WeekdayCalendar struct{}
+
+//IsPublicationDay returns false for saturdays and sundays
+func (WeekdayCalendar) IsPublicationDay(t time.Time) bool {
+	return !isWeekend(t)
+}
+
+//TargetCalendar is the publication calendar of the European Central Bank.
+//Rates are not published on weekends and TARGET2 closing days which are
+//New Year's Day, Good Friday, Easter Monday, 1 May, 25 and 26 December.
+type TargetCalendar struct{}
+
+//IsPublicationDay returns false for weekends and TARGET2 closing days
+func (TargetCalendar) IsPublicationDay(t time.Time) bool {
+	if isWeekend(t) {
+		return false
+	}
+
+	year, month, day := t.Date()
+	switch {
+	case month == time.January && day == 1,
+		month == time.May && day == 1,
+		month == time.December && (day == 25 || day == 26):
+		return false
+	}
+
+	easter := easterSunday(year)
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return !date.Equal(easter.AddDate(0, 0, -2)) && !date.Equal(easter.AddDate(0, 0, 1))
+}
+
+//HolidayCalendar adds closing days to another calendar.
+//It can be used to plug the holidays of other central banks.
+type HolidayCalendar struct {
+	calendar Calendar
+	holidays map[string]bool
+}
+
+//NewHolidayCalendar creates a calendar that does not publish rates on the
+//given holidays and the days that the given calendar does not publish.
+//Nil calendar means rates are published on weekdays.
+func NewHolidayCalendar(calendar Calendar, holidays ...time.Time) HolidayCalendar {
+	if calendar == nil {
+		calendar = WeekdayCalendar{}
+	}
+
+	h := HolidayCalendar{
+		calendar: calendar,
+		holidays: make(map[string]bool, len(holidays)),
+	}
+
+	for _, holiday := range holidays {
+		h.holidays[holiday.Format(GexcLayout)] = true
+	}
+
+	return h
+}
+
+//IsPublicationDay returns false for holidays and the
+//closing days of the underlying calendar
+func (h HolidayCalendar) IsPublicationDay(t time.Time) bool {
+	return !h.holidays[t.Format(GexcLayout)] && h.calendar.IsPublicationDay(t)
+}
+
+//IsPublicationDay tells whether rates are published at the day of t.
+//Nil calendar means TargetCalendar.
+func IsPublicationDay(calendar Calendar, t time.Time) bool {
+	return orTarget(calendar).IsPublicationDay(t)
+}
+
+//PreviousPublicationDay returns the last publication day before the day of t.
+//Nil calendar means TargetCalendar. Returns ErrNoPublicationDay if there
+//is none within a year.
+func PreviousPublicationDay(calendar Calendar, t time.Time) (time.Time, error) {
+	return LatestPublicationDay(calendar, startOfDay(t).AddDate(0, 0, -1))
+}
+
+//LatestPublicationDay returns the day of t if it is a publication day,
+//otherwise the previous publication day. Nil calendar means TargetCalendar.
+//Returns ErrNoPublicationDay if there is none within a year.
+func LatestPublicationDay(calendar Calendar, t time.Time) (time.Time, error) {
+	calendar = orTarget(calendar)
+
+	day := startOfDay(t)
+	for i := 0; i < maxSearchDays; i++ {
+		if calendar.IsPublicationDay(day) {
+			return day, nil
+		}
+
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return time.Time{}, fmt.Errorf("%w until %v", ErrNoPublicationDay, NewGexc(t))
+}
+
+//NextPublicationTime returns the first publication time after t.
+//Rates are published at PublicationHour in Central European Time on
+//publication days. Nil calendar means TargetCalendar. Returns
+//ErrNoPublicationDay if there is none within a year.
+func NextPublicationTime(calendar Calendar, t time.Time) (time.Time, error) {
+	calendar = orTarget(calendar)
+
+	local := t.In(publicationLocation)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= maxSearchDays; i++ {
+		publication := time.Date(day.Year(), day.Month(), day.Day(), PublicationHour, 0, 0, 0, publicationLocation)
+		if calendar.IsPublicationDay(day) && publication.After(t) {
+			return publication.In(t.Location()), nil
+		}
+
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}, fmt.Errorf("%w after %v", ErrNoPublicationDay, NewGexc(t))
+}
+
+//LatestPublishedDay returns the day of the last rates that are published
+//until t, taking PublicationHour into account. The day is returned in UTC.
+//Nil calendar means TargetCalendar. Returns ErrNoPublicationDay if there
+//is none within a year.
+func LatestPublishedDay(calendar Calendar, t time.Time) (time.Time, error) {
+	local := t.In(publicationLocation)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	if local.Hour() < PublicationHour {
+		return PreviousPublicationDay(calendar, day)
+	}
+
+	return LatestPublicationDay(calendar, day)
+}
+
+func orTarget(calendar Calendar) Calendar {
+	if calendar == nil {
+		return TargetCalendar{}
+	}
+
+	return calendar
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+//easterSunday calculates the date of the easter sunday
+//of the gregorian calendar by using the anonymous algorithm
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
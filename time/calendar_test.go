@@ -0,0 +1,260 @@
+package time
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWeekdayCalendar_IsPublicationDay(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{
+			name: "should return true for weekdays",
+			date: time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "should return false for saturdays",
+			date: time.Date(2020, 12, 26, 0, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "should return false for sundays",
+			date: time.Date(2020, 12, 27, 0, 0, 0, 0, time.UTC),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (WeekdayCalendar{}).IsPublicationDay(tt.date); got != tt.want {
+				t.Errorf("IsPublicationDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTargetCalendar_IsPublicationDay(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{name: "should return true for ordinary weekdays", date: date(2021, 4, 6), want: true},
+		{name: "should return false for weekends", date: date(2021, 4, 3), want: false},
+		{name: "should return false for new year", date: date(2021, 1, 1), want: false},
+		{name: "should return false for good friday", date: date(2021, 4, 2), want: false},
+		{name: "should return false for easter monday", date: date(2021, 4, 5), want: false},
+		{name: "should return false for labour day", date: date(2020, 5, 1), want: false},
+		{name: "should return false for christmas", date: date(2020, 12, 25), want: false},
+		{name: "should return false for boxing day", date: date(2019, 12, 26), want: false},
+		{name: "should return true for christmas eve", date: date(2020, 12, 24), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (TargetCalendar{}).IsPublicationDay(tt.date); got != tt.want {
+				t.Errorf("IsPublicationDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHolidayCalendar_IsPublicationDay(t *testing.T) {
+	calendar := NewHolidayCalendar(TargetCalendar{}, date(2021, 4, 23))
+
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{name: "should return false for given holidays", date: date(2021, 4, 23), want: false},
+		{name: "should return false for closing days of underlying calendar", date: date(2021, 4, 2), want: false},
+		{name: "should return true for other days", date: date(2021, 4, 22), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.IsPublicationDay(tt.date); got != tt.want {
+				t.Errorf("IsPublicationDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_easterSunday(t *testing.T) {
+	tests := []struct {
+		year int
+		want time.Time
+	}{
+		{year: 2019, want: date(2019, 4, 21)},
+		{year: 2020, want: date(2020, 4, 12)},
+		{year: 2021, want: date(2021, 4, 4)},
+		{year: 2024, want: date(2024, 3, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.Format(GexcLayout), func(t *testing.T) {
+			if got := easterSunday(tt.year); !got.Equal(tt.want) {
+				t.Errorf("easterSunday() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviousPublicationDay(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want time.Time
+	}{
+		{
+			name: "should skip weekends and holidays",
+			date: time.Date(2020, 12, 28, 18, 0, 0, 0, time.UTC),
+			want: date(2020, 12, 24),
+		},
+		{
+			name: "should return the day before for ordinary days",
+			date: date(2020, 12, 23),
+			want: date(2020, 12, 22),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := PreviousPublicationDay(nil, tt.date); err != nil || !got.Equal(tt.want) {
+				t.Errorf("PreviousPublicationDay() = %v, error = %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestPublicationDay(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want time.Time
+	}{
+		{
+			name: "should return the same day for publication days",
+			date: time.Date(2020, 12, 24, 18, 0, 0, 0, time.UTC),
+			want: date(2020, 12, 24),
+		},
+		{
+			name: "should return the previous publication day for closing days",
+			date: date(2020, 12, 27),
+			want: date(2020, 12, 24),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := LatestPublicationDay(nil, tt.date); err != nil || !got.Equal(tt.want) {
+				t.Errorf("LatestPublicationDay() = %v, error = %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextPublicationTime(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want time.Time
+	}{
+		{
+			name: "should return the same day if rates are not published yet",
+			time: time.Date(2020, 12, 24, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2020, 12, 24, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should skip holidays and weekends after publication",
+			time: time.Date(2020, 12, 24, 15, 0, 0, 0, time.UTC),
+			want: time.Date(2020, 12, 28, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should take summer time into account",
+			time: time.Date(2021, 6, 1, 14, 30, 0, 0, time.UTC),
+			want: time.Date(2021, 6, 2, 14, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := NextPublicationTime(nil, tt.time); err != nil || !got.Equal(tt.want) {
+				t.Errorf("NextPublicationTime() = %v, error = %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestPublishedDay(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want time.Time
+	}{
+		{
+			name: "should return the previous day before publication",
+			time: time.Date(2020, 12, 24, 14, 59, 0, 0, time.UTC),
+			want: time.Date(2020, 12, 23, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should return the same day after publication",
+			time: time.Date(2020, 12, 24, 15, 0, 0, 0, time.UTC),
+			want: time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should skip holidays and weekends",
+			time: time.Date(2020, 12, 28, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should take summer time into account",
+			time: time.Date(2021, 6, 1, 14, 30, 0, 0, time.UTC),
+			want: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := LatestPublishedDay(nil, tt.time); err != nil || !got.Equal(tt.want) {
+				t.Errorf("LatestPublishedDay() = %v, error = %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendar_NeverPublishes(t *testing.T) {
+	never := CalendarFunc(func(t time.Time) bool { return false })
+	now := time.Date(2020, 12, 24, 10, 0, 0, 0, time.UTC)
+
+	searches := map[string]func() (time.Time, error){
+		"PreviousPublicationDay": func() (time.Time, error) { return PreviousPublicationDay(never, now) },
+		"LatestPublicationDay":   func() (time.Time, error) { return LatestPublicationDay(never, now) },
+		"NextPublicationTime":    func() (time.Time, error) { return NextPublicationTime(never, now) },
+		"LatestPublishedDay":     func() (time.Time, error) { return LatestPublishedDay(never, now) },
+	}
+
+	for name, search := range searches {
+		t.Run(name, func(t *testing.T) {
+			if got, err := search(); !errors.Is(err, ErrNoPublicationDay) || !got.IsZero() {
+				t.Errorf("%v() = %v, error = %v, want %v", name, got, err, ErrNoPublicationDay)
+			}
+		})
+	}
+
+	//a publication day within a year is found
+	yearly := CalendarFunc(func(t time.Time) bool { return t.Month() == time.June && t.Day() == 1 })
+	if got, err := NextPublicationTime(yearly, now); err != nil || !got.Equal(time.Date(2021, 6, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextPublicationTime() = %v, error = %v", got, err)
+	}
+}
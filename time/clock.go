@@ -0,0 +1,58 @@
+package time
+
+import (
+	"sync"
+	"time"
+)
+
+//Clock tells the current time. Everything in gexc that depends on
+//the current time, like the validation of future dates and the
+//expiry of quotes, asks a Clock, so it can be controlled in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+//SystemClock is the real clock of the system
+type SystemClock struct{}
+
+//Now returns time.Now()
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+//ManualClock is a clock that only moves when it is told to.
+//It is safe for concurrent use.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+//NewManualClock creates a clock that stands at t
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+//Now returns the time that the clock stands at
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+//Set moves the clock to t
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
+
+//Advance moves the clock forward by d and returns the new time
+func (c *ManualClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	return c.now
+}
@@ -0,0 +1,33 @@
+package time
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2020, 12, 24, 15, 59, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+
+	if got := clock.Now(); !got.Equal(start) {
+		t.Errorf("Now() got = %v, want %v", got, start)
+	}
+
+	if got := clock.Advance(2 * time.Minute); !got.Equal(start.Add(2*time.Minute)) || !clock.Now().Equal(got) {
+		t.Errorf("Advance() got = %v, Now() = %v", got, clock.Now())
+	}
+
+	clock.Set(start)
+	if got := clock.Now(); !got.Equal(start) {
+		t.Errorf("Set() got = %v, want %v", got, start)
+	}
+}
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	got := SystemClock{}.Now()
+
+	if got.Before(before) || got.After(time.Now()) {
+		t.Errorf("Now() got = %v, want current time", got)
+	}
+}
@@ -0,0 +1,199 @@
+package gexc
+
+import (
+	"context"
+	"fmt"
+	"github.com/fufuceng/gexc/response"
+	gtime "github.com/fufuceng/gexc/time"
+	"time"
+)
+
+const (
+	defaultWatchRetry    = time.Minute
+	defaultWatchMaxRetry = 30 * time.Minute
+)
+
+//WatchOption customizes the polling of Watch
+type WatchOption func(w *watcher)
+
+//WithWatchRetry sets the first and the maximum intervals of the polling
+//while the expected rates are not published yet or the provider fails.
+//The interval doubles after every attempt, defaults are 1 and 30 minutes.
+func WithWatchRetry(first, max time.Duration) WatchOption {
+	return func(w *watcher) {
+		if first > 0 {
+			w.retry = first
+		}
+
+		if max >= w.retry {
+			w.maxRetry = max
+		}
+	}
+}
+
+//WithWatchErrors sets the function that receives the failures of the
+//provider and the calendar, which are retried. Failures are ignored
+//by default.
+func WithWatchErrors(handler func(error)) WatchOption {
+	return func(w *watcher) {
+		w.onError = handler
+	}
+}
+
+type watcher struct {
+	fx       *Fx
+	rates    *fxRatesFromWrapper
+	retry    time.Duration
+	maxRetry time.Duration
+	onError  func(error)
+	//sleep waits for d unless the context is done
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+//Watch is the alternative last step of the rates. It polls the latest
+//rates and delivers every newly published rate table on the returned
+//channel until the context is cancelled, then closes the channel.
+//The current table is delivered first. Polling sleeps until the next
+//publication time of the calendar, see WithCalendar, and retries with
+//a growing interval until the rates of a new date are returned, so
+//tables are delivered once per date even if they are fetched again.
+//
+//	tables, err := fx.BasedOn("EUR").Against("TRY").Watch(ctx)
+//	if err != nil {
+//		...
+//	}
+//	for table := range tables {
+//		fmt.Println(table.Date, table.Rates)
+//	}
+func (f *fxRatesFromWrapper) Watch(ctx context.Context, options ...WatchOption) (<-chan response.SingleDate, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	//a calendar that never publishes would poll forever
+	if _, err := gtime.NextPublicationTime(f.base.calendar, f.base.now()); err != nil {
+		return nil, err
+	}
+
+	w := &watcher{
+		fx:       f.base,
+		rates:    f,
+		retry:    defaultWatchRetry,
+		maxRetry: defaultWatchMaxRetry,
+		sleep:    sleep,
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	tables := make(chan response.SingleDate)
+	go w.run(ctx, tables)
+	return tables, nil
+}
+
+//validate checks the currencies before polling, since the
+//failures of Latest are retried
+func (f *fxRatesFromWrapper) validate() error {
+	for _, code := range append([]string{f.baseCurrency}, f.against...) {
+		if _, ok := CurrencyByCode(code); !ok {
+			return fmt.Errorf("%w: %v", ErrUnsupportedCurrency, code)
+		}
+	}
+
+	return nil
+}
+
+func (w *watcher) run(ctx context.Context, tables chan<- response.SingleDate) {
+	defer close(tables)
+
+	var last time.Time
+	retry := w.retry
+	for {
+		resp, err := w.rates.Latest()
+		now := w.fx.now()
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			w.report(err)
+			wait, retry = w.backoff(now, retry)
+		case last.IsZero() || truncateDay(resp.Date.Time).After(last):
+			select {
+			case tables <- resp:
+			case <-ctx.Done():
+				return
+			}
+
+			last = truncateDay(resp.Date.Time)
+			retry = w.retry
+			wait = w.untilPublication(now)
+		case w.publishedAfter(now, last):
+			//the rates are published but the provider does not serve them yet
+			wait, retry = w.backoff(now, retry)
+		default:
+			retry = w.retry
+			wait = w.untilPublication(now)
+		}
+
+		if err := w.sleep(ctx, wait); err != nil {
+			return
+		}
+	}
+}
+
+//backoff returns the interval of the next attempt, which is not
+//after the next publication, and the interval after it
+func (w *watcher) backoff(now time.Time, retry time.Duration) (time.Duration, time.Duration) {
+	wait := retry
+	if untilPublication := w.untilPublication(now); untilPublication < wait {
+		wait = untilPublication
+	}
+
+	if retry *= 2; retry > w.maxRetry {
+		retry = w.maxRetry
+	}
+
+	return wait, retry
+}
+
+//untilPublication returns the time until the next publication,
+//or the maximum interval if the calendar has none
+func (w *watcher) untilPublication(now time.Time) time.Duration {
+	next, err := gtime.NextPublicationTime(w.fx.calendar, now)
+	if err != nil {
+		w.report(err)
+		return w.maxRetry
+	}
+
+	return next.Sub(now)
+}
+
+//publishedAfter tells whether rates of a day after last are published until now
+func (w *watcher) publishedAfter(now, last time.Time) bool {
+	published, err := gtime.LatestPublishedDay(w.fx.calendar, now)
+	if err != nil {
+		w.report(err)
+		return false
+	}
+
+	return published.After(last)
+}
+
+func (w *watcher) report(err error) {
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
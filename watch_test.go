@@ -0,0 +1,152 @@
+package gexc
+
+import (
+	"context"
+	"errors"
+	"github.com/fufuceng/gexc/internal/openex"
+	"github.com/fufuceng/gexc/response"
+	time2 "github.com/fufuceng/gexc/time"
+	"github.com/fufuceng/gexc/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+//watchTestClient serves the latest rates that are published until
+//lag before the time of the clock, the rate is the day of the date
+type watchTestClient struct {
+	clock *time2.ManualClock
+	lag   time.Duration
+	fails int
+}
+
+func (w *watchTestClient) Latest(params openex.LatestParams) (*response.SingleDate, error) {
+	if w.fails > 0 {
+		w.fails--
+		return nil, errors.New("connection refused")
+	}
+
+	date, err := time2.LatestPublishedDay(nil, w.clock.Now().Add(-w.lag))
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.SingleDate{
+		Base:  params.Base,
+		Rates: types.RateItem{"TRY": float64(date.Day())},
+		Date:  time2.NewGexc(date),
+	}, nil
+}
+
+func (w *watchTestClient) SingleDate(params openex.SingleDateParams) (*response.SingleDate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (w *watchTestClient) History(params openex.HistoryParams) (*response.History, error) {
+	return nil, errors.New("not implemented")
+}
+
+//withSleep replaces the waiting of the watcher by advancing the clock
+func withSleep(clock *time2.ManualClock, waits *[]time.Duration) WatchOption {
+	return func(w *watcher) {
+		w.sleep = func(ctx context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			clock.Advance(d)
+			return ctx.Err()
+		}
+	}
+}
+
+func TestFx_Watch(t *testing.T) {
+	table := func(day int) response.SingleDate {
+		return response.SingleDate{
+			Base:  "EUR",
+			Rates: types.RateItem{"TRY": float64(day)},
+			Date:  time2.NewGexc(time.Date(2020, 12, day, 0, 0, 0, 0, time.UTC)),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		fails     int
+		want      []response.SingleDate
+		wantWaits []time.Duration
+		wantErrs  int
+	}{
+		{
+			name: "should poll around the publication times until new rates are served",
+			want: []response.SingleDate{table(23), table(24), table(28)},
+			wantWaits: []time.Duration{
+				//until the publication of 2020-12-24 at 15:00 UTC
+				3 * time.Hour,
+				//the provider serves the new rates 30 minutes late
+				10 * time.Minute,
+				20 * time.Minute,
+				//2020-12-25 and 2020-12-26 are holidays
+				95*time.Hour + 30*time.Minute,
+				10 * time.Minute,
+				20 * time.Minute,
+			},
+		},
+		{
+			name:      "should retry the failures of the provider",
+			fails:     3,
+			want:      []response.SingleDate{table(23), table(24)},
+			wantWaits: []time.Duration{10 * time.Minute, 20 * time.Minute, 20 * time.Minute, 2*time.Hour + 10*time.Minute},
+			wantErrs:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time2.NewManualClock(time.Date(2020, 12, 24, 12, 0, 0, 0, time.UTC))
+			client := &watchTestClient{clock: clock, lag: 30 * time.Minute, fails: tt.fails}
+			f := newFxWithClient(client, WithClock(clock))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var waits []time.Duration
+			var errs int
+			tables, err := f.BasedOn("EUR").Against("TRY").Watch(ctx,
+				WithWatchRetry(10*time.Minute, 20*time.Minute),
+				WithWatchErrors(func(error) { errs++ }),
+				withSleep(clock, &waits))
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+
+			var got []response.SingleDate
+			for resp := range tables {
+				if got = append(got, resp); len(got) == len(tt.want) {
+					cancel()
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Watch() got = %v, want %v", got, tt.want)
+			}
+
+			if len(waits) < len(tt.wantWaits) || !reflect.DeepEqual(waits[:len(tt.wantWaits)], tt.wantWaits) {
+				t.Errorf("Watch() waits = %v, want %v", waits, tt.wantWaits)
+			}
+
+			if errs != tt.wantErrs {
+				t.Errorf("Watch() errors = %v, want %v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestFx_WatchValidation(t *testing.T) {
+	f := newFxWithClient(&watchTestClient{})
+	if _, err := f.BasedOn("EUR").Against("UNKNOWN").Watch(context.Background()); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Watch() error = %v, want %v", err, ErrUnsupportedCurrency)
+	}
+
+	never := time2.CalendarFunc(func(t time.Time) bool { return false })
+	f = newFxWithClient(&watchTestClient{}, WithCalendar(never))
+	if _, err := f.BasedOn("EUR").Against("TRY").Watch(context.Background()); !errors.Is(err, time2.ErrNoPublicationDay) {
+		t.Errorf("Watch() error = %v, want %v", err, time2.ErrNoPublicationDay)
+	}
+}